@@ -1,13 +1,27 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/NMFR/sqlc-template/internal/cli"
 	"github.com/NMFR/sqlc-template/internal/code"
 )
 
 func main() {
-	if err := code.GenerateFromReader(os.Stdin, os.Stdout); err != nil {
+	if len(os.Args) > 1 && cli.HasCommand(os.Args[1]) {
+		if err := cli.Run(os.Args[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	// sqlc does not tell the plugin where its config file is, the `SQLC_TEMPLATE_ROOT` environment variable (passed by
+	// sqlc when listed in the plugin `env` config) sets the directory the relative template file paths are resolved from.
+	config := code.Config{TemplateRoot: os.Getenv(code.TemplateRootEnv)}
+	if err := code.GenerateFromReader(os.Stdin, os.Stdout, config); err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+// command runs a command line subcommand with the arguments that follow the subcommand name.
+type command = func(args []string, stdout io.Writer) error
+
+var commands = map[string]command{
+	"dump":   dump,
+	"render": render,
+}
+
+// HasCommand returns true if name is a command line subcommand.
+// When sqlc runs the plugin as a process plugin the first argument is the gRPC method name, not a subcommand.
+func HasCommand(name string) bool {
+	_, ok := commands[name]
+
+	return ok
+}
+
+// Run runs the command line subcommand named by the first argument.
+func Run(args []string, stdout io.Writer) error {
+	if len(args) == 0 || !HasCommand(args[0]) {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return fmt.Errorf("missing or unknown subcommand, must be one of: %s", strings.Join(names, ", "))
+	}
+
+	return commands[args[0]](args[1:], stdout)
+}
+
+// readRequest reads a sqlc plugin GenerateRequest from a file.
+// Files with the ".json" extension are decoded as protobuf JSON, every other file as binary protobuf.
+func readRequest(path string) (*plugin.GenerateRequest, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the request file %q, %w", path, err)
+	}
+
+	request := &plugin.GenerateRequest{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := protojson.Unmarshal(in, request); err != nil {
+			return nil, fmt.Errorf("failed to parse / unmarshal the JSON sqlc plugin GenerateRequest %q, %w", path, err)
+		}
+
+		return request, nil
+	}
+
+	if err := proto.Unmarshal(in, request); err != nil {
+		return nil, fmt.Errorf("failed to parse / unmarshal the sqlc plugin GenerateRequest %q, %w", path, err)
+	}
+
+	return request, nil
+}
+
+// writeFiles writes the generated files into the dir directory and prints each written file path.
+// The file names are checked before writing any file, absolute names and names outside the dir directory are rejected.
+func writeFiles(dir string, files []*plugin.File, stdout io.Writer) error {
+	for _, file := range files {
+		if !filepath.IsLocal(file.GetName()) {
+			return fmt.Errorf(
+				"the generated file name %q must be a relative path inside the %q directory", file.GetName(), dir,
+			)
+		}
+	}
+
+	for _, file := range files {
+		path := filepath.Join(dir, file.GetName())
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create the directory of the file %q, %w", path, err)
+		}
+
+		if err := os.WriteFile(path, file.GetContents(), 0o644); err != nil {
+			return fmt.Errorf("failed to write the file %q, %w", path, err)
+		}
+
+		if _, err := fmt.Fprintln(stdout, path); err != nil {
+			return fmt.Errorf("failed to write to the stream, %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+)
+
+// dump prints a GenerateRequest captured from sqlc as the templates see it.
+func dump(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("dump", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	requestPath := flags.String(
+		"request", "", "The GenerateRequest file path, binary protobuf or protobuf JSON if the extension is \".json\".",
+	)
+	format := flags.String("format", code.DumpFormatYAML, fmt.Sprintf("The output format, one of: %q.", code.DumpFormats))
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if *requestPath == "" {
+		return fmt.Errorf("missing the -request flag")
+	}
+
+	request, err := readRequest(*requestPath)
+	if err != nil {
+		return err
+	}
+
+	out, err := code.DumpRequest(request, *format)
+	if err != nil {
+		return err
+	}
+
+	if _, err := stdout.Write(out); err != nil {
+		return fmt.Errorf("failed to write to the stream, %w", err)
+	}
+
+	return nil
+}
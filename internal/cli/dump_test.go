@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/NMFR/sqlc-template/internal/cli"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestDump(t *testing.T) {
+	dir := t.TempDir()
+	request, err := proto.Marshal(&plugin.GenerateRequest{SqlcVersion: "v1.28.0"})
+	assert.NoError(t, err)
+	requestPath := writeFile(t, filepath.Join(dir, "request.pb"), request)
+
+	testCases := map[string]struct {
+		args           []string
+		expected       string
+		expectedErrMsg string
+	}{
+		"yaml": {
+			args: []string{"dump", "-request", requestPath},
+			expected: `Settings: null
+Catalog: null
+Queries: []
+SqlcVersion: v1.28.0
+PluginOptions: null
+GlobalOptions: null
+`,
+		},
+		"json": {
+			args: []string{"dump", "-request", requestPath, "-format", "json"},
+			expected: `{
+  "Settings": null,
+  "Catalog": null,
+  "Queries": [],
+  "SqlcVersion": "v1.28.0",
+  "PluginOptions": null,
+  "GlobalOptions": null
+}
+`,
+		},
+		"missing request flag": {
+			args:           []string{"dump"},
+			expectedErrMsg: "missing the -request flag",
+		},
+		"missing request file": {
+			args:           []string{"dump", "-request", filepath.Join(dir, "missing.pb")},
+			expectedErrMsg: "failed to read the request file",
+		},
+		"invalid format": {
+			args:           []string{"dump", "-request", requestPath, "-format", "xml"},
+			expectedErrMsg: "invalid dump format \"xml\"",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			err := cli.Run(testCase.args, stdout)
+
+			if testCase.expectedErrMsg != "" {
+				assert.ErrorContains(t, err, testCase.expectedErrMsg)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, stdout.String())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+)
+
+// render renders the templates against a GenerateRequest captured from sqlc and writes the generated files to disk.
+func render(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	requestPath := flags.String(
+		"request", "", "The GenerateRequest file path, binary protobuf or protobuf JSON if the extension is \".json\".",
+	)
+	optionsPath := flags.String("options", "", "A JSON file path that replaces the request plugin options.")
+	templatePath := flags.String("template", "", "A template file path that replaces the 'template' option.")
+	filename := flags.String("filename", "", "A file name template that replaces the 'filename' option.")
+	out := flags.String("out", ".", "The directory where the generated files are written to.")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if *requestPath == "" {
+		return fmt.Errorf("missing the -request flag")
+	}
+
+	request, err := readRequest(*requestPath)
+	if err != nil {
+		return err
+	}
+
+	if *optionsPath != "" {
+		options, err := os.ReadFile(*optionsPath)
+		if err != nil {
+			return fmt.Errorf("failed to read the options file %q, %w", *optionsPath, err)
+		}
+		request.PluginOptions = options
+	}
+
+	if *templatePath != "" || *filename != "" {
+		options := map[string]any{}
+		if len(request.GetPluginOptions()) > 0 {
+			if err := json.Unmarshal(request.GetPluginOptions(), &options); err != nil {
+				return fmt.Errorf("failed to parse the plugin options to JSON, %w", err)
+			}
+		}
+
+		if *templatePath != "" {
+			// The flag path is relative to the working directory, not to the 'template_root' option.
+			path, err := filepath.Abs(*templatePath)
+			if err != nil {
+				return fmt.Errorf("failed to resolve the template file path %q, %w", *templatePath, err)
+			}
+
+			delete(options, "template")
+			options["template_file"] = path
+		}
+		if *filename != "" {
+			options["filename"] = *filename
+		}
+
+		request.PluginOptions, err = json.Marshal(options)
+		if err != nil {
+			return fmt.Errorf("failed to format the plugin options to JSON, %w", err)
+		}
+	}
+
+	response, err := code.GenerateWithConfig(request, code.Config{TemplateRoot: os.Getenv(code.TemplateRootEnv)})
+	if err != nil {
+		return err
+	}
+
+	return writeFiles(*out, response.GetFiles(), stdout)
+}
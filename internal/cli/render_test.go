@@ -0,0 +1,195 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/NMFR/sqlc-template/internal/cli"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func writeFile(t *testing.T, path string, contents []byte) string {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, contents, 0o600))
+
+	return path
+}
+
+func TestRenderSuccess(t *testing.T) {
+	dir := t.TempDir()
+	request := &plugin.GenerateRequest{
+		SqlcVersion: "v1.28.0",
+		Queries:     []*plugin.Query{{Name: "GetAuthor"}, {Name: "ListAuthors"}},
+		PluginOptions: []byte(`{
+			"mode": "per-query",
+			"filename": "{{ .Name | ToSnake }}.txt",
+			"template": "{{ .Name }}"
+		}`),
+	}
+
+	binaryRequest, err := proto.Marshal(request)
+	assert.NoError(t, err)
+	jsonRequest, err := protojson.Marshal(request)
+	assert.NoError(t, err)
+
+	binaryRequestPath := writeFile(t, filepath.Join(dir, "request.pb"), binaryRequest)
+	jsonRequestPath := writeFile(t, filepath.Join(dir, "request.json"), jsonRequest)
+	templatePath := writeFile(t, filepath.Join(dir, "query.tmpl"), []byte(`{{ .Name }} {{ .Request.SqlcVersion }}`))
+	optionsPath := writeFile(t, filepath.Join(dir, "options.json"), []byte(`{
+		"filename": "all.txt",
+		"template": "{{ range .Queries }}{{ .Name }};{{ end }}"
+	}`))
+	rootOptionsPath := writeFile(t, filepath.Join(dir, "root-options.json"), []byte(`{
+		"mode": "per-query",
+		"filename": "{{ .Name }}.txt",
+		"template_root": "`+filepath.Join(dir, "root")+`",
+		"template": ""
+	}`))
+
+	testCases := map[string]struct {
+		args     []string
+		chdir    string
+		expected map[string]string
+	}{
+		"binary request": {
+			args: []string{"render", "-request", binaryRequestPath},
+			expected: map[string]string{
+				"get_author.txt":   "GetAuthor",
+				"list_authors.txt": "ListAuthors",
+			},
+		},
+		"json request": {
+			args: []string{"render", "-request", jsonRequestPath},
+			expected: map[string]string{
+				"get_author.txt":   "GetAuthor",
+				"list_authors.txt": "ListAuthors",
+			},
+		},
+		"template and filename flags": {
+			args: []string{
+				"render", "-request", binaryRequestPath, "-template", templatePath, "-filename", "gen/{{ .Name }}.go",
+			},
+			expected: map[string]string{
+				"gen/GetAuthor.go":   "GetAuthor v1.28.0",
+				"gen/ListAuthors.go": "ListAuthors v1.28.0",
+			},
+		},
+		"relative template flag with a template_root option": {
+			args:  []string{"render", "-request", binaryRequestPath, "-options", rootOptionsPath, "-template", "query.tmpl"},
+			chdir: dir,
+			expected: map[string]string{
+				"GetAuthor.txt":   "GetAuthor v1.28.0",
+				"ListAuthors.txt": "ListAuthors v1.28.0",
+			},
+		},
+		"options flag": {
+			args: []string{"render", "-request", jsonRequestPath, "-options", optionsPath},
+			expected: map[string]string{
+				"all.txt": "GetAuthor;ListAuthors;",
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			out := t.TempDir()
+			stdout := &bytes.Buffer{}
+			if testCase.chdir != "" {
+				t.Chdir(testCase.chdir)
+			}
+
+			err := cli.Run(append(testCase.args, "-out", out), stdout)
+			assert.NoError(t, err)
+
+			for name, expected := range testCase.expected {
+				contents, err := os.ReadFile(filepath.Join(out, name))
+				assert.NoError(t, err)
+				assert.Equal(t, expected, string(contents))
+				assert.Contains(t, stdout.String(), filepath.Join(out, name))
+			}
+		})
+	}
+}
+
+func TestRenderFailure(t *testing.T) {
+	dir := t.TempDir()
+	invalidRequestPath := writeFile(t, filepath.Join(dir, "request.pb"), []byte("not a binary protobuf message at all"))
+	invalidJSONRequestPath := writeFile(t, filepath.Join(dir, "request.json"), []byte("not a JSON at all"))
+	emptyRequestPath := writeFile(t, filepath.Join(dir, "empty.pb"), []byte{})
+	templateOptionsPath := writeFile(t, filepath.Join(dir, "options.json"), []byte(`{"template": ""}`))
+
+	testCases := map[string]struct {
+		args           []string
+		expectedErrMsg string
+	}{
+		"missing subcommand": {
+			args:           []string{},
+			expectedErrMsg: "missing or unknown subcommand, must be one of: dump, render",
+		},
+		"unknown subcommand": {
+			args:           []string{"/plugin.CodegenService/Generate"},
+			expectedErrMsg: "missing or unknown subcommand",
+		},
+		"unknown flag": {
+			args:           []string{"render", "-unknown"},
+			expectedErrMsg: "flag provided but not defined: -unknown",
+		},
+		"missing request flag": {
+			args:           []string{"render"},
+			expectedErrMsg: "missing the -request flag",
+		},
+		"missing request file": {
+			args:           []string{"render", "-request", filepath.Join(dir, "missing.pb")},
+			expectedErrMsg: "failed to read the request file",
+		},
+		"invalid binary request": {
+			args:           []string{"render", "-request", invalidRequestPath},
+			expectedErrMsg: "failed to parse / unmarshal the sqlc plugin GenerateRequest",
+		},
+		"invalid JSON request": {
+			args:           []string{"render", "-request", invalidJSONRequestPath},
+			expectedErrMsg: "failed to parse / unmarshal the JSON sqlc plugin GenerateRequest",
+		},
+		"missing options file": {
+			args:           []string{"render", "-request", emptyRequestPath, "-options", filepath.Join(dir, "missing.json")},
+			expectedErrMsg: "failed to read the options file",
+		},
+		"file name outside the out directory": {
+			args: []string{
+				"render", "-request", emptyRequestPath, "-options", templateOptionsPath, "-filename", "../../escape.txt",
+				"-out", filepath.Join(dir, "out"),
+			},
+			expectedErrMsg: "the generated file name \"../../escape.txt\" must be a relative path inside the",
+		},
+		"absolute file name": {
+			args: []string{
+				"render", "-request", emptyRequestPath, "-options", templateOptionsPath,
+				"-filename", filepath.Join(dir, "absolute.txt"), "-out", filepath.Join(dir, "out"),
+			},
+			expectedErrMsg: "must be a relative path inside the",
+		},
+		"generate error": {
+			args:           []string{"render", "-request", emptyRequestPath, "-filename", "test.file"},
+			expectedErrMsg: "missing the sqlc 'sql[].codegen.options.template' field",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			err := cli.Run(testCase.args, &bytes.Buffer{})
+
+			assert.Error(t, err)
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
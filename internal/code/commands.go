@@ -0,0 +1,105 @@
+package code
+
+import (
+	"fmt"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+// The sqlc query commands, the `:name` annotation of a query.
+const (
+	commandOne        = ":one"
+	commandMany       = ":many"
+	commandExec       = ":exec"
+	commandExecRows   = ":execrows"
+	commandExecResult = ":execresult"
+	commandExecLastID = ":execlastid"
+	commandCopyFrom   = ":copyfrom"
+	commandBatchExec  = ":batchexec"
+	commandBatchMany  = ":batchmany"
+	commandBatchOne   = ":batchone"
+)
+
+var commands = []string{
+	commandOne, commandMany, commandExec, commandExecRows, commandExecResult, commandExecLastID, commandCopyFrom,
+	commandBatchExec, commandBatchMany, commandBatchOne,
+}
+
+// queryCommand is the parsed command of a query.
+type queryCommand struct {
+	// Name is the command, for example ":one".
+	Name string
+	// IsKnown is false for the commands unknown to the plugin, every other field is then false.
+	IsKnown bool
+	// IsExec is true for the commands that do not return rows: ":exec", ":execrows", ":execresult", ":execlastid"
+	// and ":batchexec".
+	IsExec bool
+	// IsBatch is true for the ":batchexec", ":batchmany" and ":batchone" commands.
+	IsBatch bool
+	// IsCopyFrom is true for the ":copyfrom" command.
+	IsCopyFrom bool
+	// ReturnsRows is true for the commands that return rows: ":one", ":many", ":batchmany" and ":batchone".
+	ReturnsRows bool
+	// ReturnsSingle is true for the commands that return a single row: ":one" and ":batchone".
+	ReturnsSingle bool
+	// ReturnsAffectedRows is true for the ":execrows" command.
+	ReturnsAffectedRows bool
+	// ReturnsResult is true for the ":execresult" command.
+	ReturnsResult bool
+	// ReturnsLastID is true for the ":execlastid" command.
+	ReturnsLastID bool
+}
+
+// String returns the command name, so that `{{ .Command }}` prints the command like `{{ .Cmd }}`.
+func (c *queryCommand) String() string {
+	return c.Name
+}
+
+// parseCommand returns the parsed command of a query. In strict mode an unknown command fails.
+func parseCommand(query *plugin.Query, strict bool) (*queryCommand, error) {
+	name := query.GetCmd()
+	command := &queryCommand{Name: name}
+	switch name {
+	case commandOne:
+		command.ReturnsRows, command.ReturnsSingle = true, true
+	case commandMany:
+		command.ReturnsRows = true
+	case commandExec:
+		command.IsExec = true
+	case commandExecRows:
+		command.IsExec, command.ReturnsAffectedRows = true, true
+	case commandExecResult:
+		command.IsExec, command.ReturnsResult = true, true
+	case commandExecLastID:
+		command.IsExec, command.ReturnsLastID = true, true
+	case commandCopyFrom:
+		command.IsCopyFrom = true
+	case commandBatchExec:
+		command.IsBatch, command.IsExec = true, true
+	case commandBatchMany:
+		command.IsBatch, command.ReturnsRows = true, true
+	case commandBatchOne:
+		command.IsBatch, command.ReturnsRows, command.ReturnsSingle = true, true, true
+	default:
+		if strict {
+			return nil, fmt.Errorf(
+				"strict mode: the query %q command %q is unknown, must be one of: %q", query.GetName(), name, commands,
+			)
+		}
+
+		return command, nil
+	}
+	command.IsKnown = true
+
+	return command, nil
+}
+
+// Command returns the parsed command of a query.
+func (r *requestTemplateData) Command(query *plugin.Query) (*queryCommand, error) {
+	return parseCommand(query, r.strict)
+}
+
+// Command returns the parsed command of the query.
+func (q *queryTemplateData) Command() (*queryCommand, error) {
+	return parseCommand(q.Query, q.Request.strict)
+}
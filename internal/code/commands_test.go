@@ -0,0 +1,110 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestCommands(t *testing.T) {
+	// commandTestTemplate prints the name of the true command fields.
+	commandTestTemplate := `{{ .Command }}:{{ with .Command }}` +
+		`{{ if .IsKnown }} known{{ end }}{{ if .IsExec }} exec{{ end }}{{ if .IsBatch }} batch{{ end }}` +
+		`{{ if .IsCopyFrom }} copyfrom{{ end }}{{ if .ReturnsRows }} rows{{ end }}{{ if .ReturnsSingle }} single{{ end }}` +
+		`{{ if .ReturnsAffectedRows }} affected{{ end }}{{ if .ReturnsResult }} result{{ end }}` +
+		`{{ if .ReturnsLastID }} lastid{{ end }}{{ end }}`
+
+	testCases := map[string]struct {
+		cmd              string
+		expectedContents string
+	}{
+		":one":        {cmd: ":one", expectedContents: ":one: known rows single"},
+		":many":       {cmd: ":many", expectedContents: ":many: known rows"},
+		":exec":       {cmd: ":exec", expectedContents: ":exec: known exec"},
+		":execrows":   {cmd: ":execrows", expectedContents: ":execrows: known exec affected"},
+		":execresult": {cmd: ":execresult", expectedContents: ":execresult: known exec result"},
+		":execlastid": {cmd: ":execlastid", expectedContents: ":execlastid: known exec lastid"},
+		":copyfrom":   {cmd: ":copyfrom", expectedContents: ":copyfrom: known copyfrom"},
+		":batchexec":  {cmd: ":batchexec", expectedContents: ":batchexec: known exec batch"},
+		":batchmany":  {cmd: ":batchmany", expectedContents: ":batchmany: known batch rows"},
+		":batchone":   {cmd: ":batchone", expectedContents: ":batchone: known batch rows single"},
+		"unknown":     {cmd: ":stream", expectedContents: ":stream:"},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			response, err := code.Generate(&plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor", Cmd: testCase.cmd}},
+				PluginOptions: []byte(`{
+					"outputs": [
+						{
+							"filename": "single.txt",
+							"template": "` + jsonString(`{{ range .Queries }}{{ with $.Command . }}{{ .Name }}{{ end }}{{ end }}`) + `"
+						},
+						{
+							"filename": "{{ .Name }}.txt",
+							"mode": "per-query",
+							"template": "` + jsonString(commandTestTemplate) + `"
+						}
+					]
+				}`),
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, testCase.cmd, string(response.Files[0].Contents))
+			assert.Equal(t, testCase.expectedContents, string(response.Files[1].Contents))
+		})
+	}
+}
+
+func TestCommandsStrictMode(t *testing.T) {
+	testCases := map[string]struct {
+		template       string
+		mode           string
+		expectedErrMsg string
+	}{
+		"single mode": {
+			template: `{{ range .Queries }}{{ ($.Command .).ReturnsRows }}{{ end }}`,
+			expectedErrMsg: `failed to execute the template, template: template:1:25: executing "template" at <$.Command>: ` +
+				`error calling Command: strict mode: the query "StreamAuthors" command ":stream" is unknown, must be one ` +
+				`of: [":one" ":many" ":exec" ":execrows" ":execresult" ":execlastid" ":copyfrom" ":batchexec" ` +
+				`":batchmany" ":batchone"]`,
+		},
+		"per-query mode": {
+			template: `{{ .Command.ReturnsRows }}`,
+			mode:     "per-query",
+			expectedErrMsg: `failed to execute the template for the query "StreamAuthors", template: template:1:11: ` +
+				`executing "template" at <.Command.ReturnsRows>: error calling Command: strict mode: the query ` +
+				`"StreamAuthors" command ":stream" is unknown`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			mode, filename := "single", "queries.txt"
+			if testCase.mode != "" {
+				mode, filename = testCase.mode, "{{ .Name }}.txt"
+			}
+
+			_, err := code.Generate(&plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor", Cmd: ":one"}, {Name: "StreamAuthors", Cmd: ":stream"}},
+				PluginOptions: []byte(`{
+					"strict": true,
+					"mode": "` + mode + `",
+					"filename": "` + filename + `",
+					"template": "` + jsonString(testCase.template) + `"
+				}`),
+			})
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
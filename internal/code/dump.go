@@ -0,0 +1,172 @@
+package code
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+const (
+	// DumpFormatJSON dumps the template data view of the request as JSON.
+	DumpFormatJSON = "json"
+	// DumpFormatYAML dumps the template data view of the request as YAML.
+	DumpFormatYAML = "yaml"
+	// DumpFormatProtobuf dumps the request as binary protobuf, the format sqlc sends to the plugin.
+	DumpFormatProtobuf = "pb"
+)
+
+var DumpFormats = []string{DumpFormatJSON, DumpFormatYAML, DumpFormatProtobuf}
+
+// dumpObject is a struct dumped as an object with its fields in declaration order.
+type dumpObject []dumpField
+
+type dumpField struct {
+	Name  string
+	Value any
+}
+
+func (o dumpObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (o dumpObject) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range o {
+		value := &yaml.Node{}
+		if err := value.Encode(field.Value); err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: field.Name}, value)
+	}
+
+	return node, nil
+}
+
+// dumpValue converts a value into the generic structure dumped to template authors.
+// Structs keep only their exported fields (the fields reachable from the templates) named as in Go and byte slices
+// containing JSON, like the plugin options, are decoded.
+func dumpValue(value reflect.Value) any {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+
+		return dumpValue(value.Elem())
+	case reflect.Struct:
+		object := dumpObject{}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			object = append(object, dumpField{Name: field.Name, Value: dumpValue(value.Field(i))})
+		}
+
+		return object
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			if value.Len() == 0 {
+				return nil
+			}
+
+			var decoded any
+			if err := json.Unmarshal(value.Bytes(), &decoded); err == nil {
+				return decoded
+			}
+
+			return string(value.Bytes())
+		}
+
+		list := make([]any, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			list = append(list, dumpValue(value.Index(i)))
+		}
+
+		return list
+	default:
+		return value.Interface()
+	}
+}
+
+// DumpRequest formats the request in one of the DumpFormats.
+// The JSON and YAML formats show the data exactly as the templates see it, using the Go field names.
+func DumpRequest(request *plugin.GenerateRequest, format string) ([]byte, error) {
+	switch format {
+	case DumpFormatJSON:
+		out, err := json.MarshalIndent(dumpValue(reflect.ValueOf(request)), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format the sqlc plugin GenerateRequest to JSON, %w", err)
+		}
+
+		return append(out, '\n'), nil
+	case DumpFormatYAML:
+		buf := bytes.Buffer{}
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(dumpValue(reflect.ValueOf(request))); err != nil {
+			return nil, fmt.Errorf("failed to format the sqlc plugin GenerateRequest to YAML, %w", err)
+		}
+
+		return buf.Bytes(), nil
+	case DumpFormatProtobuf:
+		out, err := proto.Marshal(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format / marshal the sqlc plugin GenerateRequest, %w", err)
+		}
+
+		return out, nil
+	default:
+		return nil, fmt.Errorf("invalid dump format %q, must be one of: %q", format, DumpFormats)
+	}
+}
+
+// getDumpFormat returns the dump format matching the filename extension.
+func getDumpFormat(filename string) (string, error) {
+	switch extension := strings.ToLower(filepath.Ext(filename)); extension {
+	case ".json":
+		return DumpFormatJSON, nil
+	case ".yaml", ".yml":
+		return DumpFormatYAML, nil
+	case ".pb":
+		return DumpFormatProtobuf, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid sqlc config '%s.debug_dump' field file extension %q, must be one of: %q",
+			optionsPath, extension, []string{".json", ".yaml", ".yml", ".pb"},
+		)
+	}
+}
@@ -0,0 +1,147 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func createDumpTestGenerateRequest() *plugin.GenerateRequest {
+	return &plugin.GenerateRequest{
+		SqlcVersion: "v1.28.0",
+		Queries: []*plugin.Query{
+			{
+				Name: "GetAuthor",
+				Cmd:  ":one",
+				Columns: []*plugin.Column{
+					{Name: "id", NotNull: true, Type: &plugin.Identifier{Name: "bigserial"}},
+				},
+			},
+		},
+		PluginOptions: []byte(`{"filename": "test.file"}`),
+	}
+}
+
+func TestDumpRequest(t *testing.T) {
+	testCases := map[string]struct {
+		format   string
+		expected string
+	}{
+		"json": {
+			format: code.DumpFormatJSON,
+			expected: `{
+  "Settings": null,
+  "Catalog": null,
+  "Queries": [
+    {
+      "Text": "",
+      "Name": "GetAuthor",
+      "Cmd": ":one",
+      "Columns": [
+        {
+          "Name": "id",
+          "NotNull": true,
+          "IsArray": false,
+          "Comment": "",
+          "Length": 0,
+          "IsNamedParam": false,
+          "IsFuncCall": false,
+          "Scope": "",
+          "Table": null,
+          "TableAlias": "",
+          "Type": {
+            "Catalog": "",
+            "Schema": "",
+            "Name": "bigserial"
+          },
+          "IsSqlcSlice": false,
+          "EmbedTable": null,
+          "OriginalName": "",
+          "Unsigned": false,
+          "ArrayDims": 0
+        }
+      ],
+      "Params": [],
+      "Comments": [],
+      "Filename": "",
+      "InsertIntoTable": null
+    }
+  ],
+  "SqlcVersion": "v1.28.0",
+  "PluginOptions": {
+    "filename": "test.file"
+  },
+  "GlobalOptions": null
+}
+`,
+		},
+		"yaml": {
+			format: code.DumpFormatYAML,
+			expected: `Settings: null
+Catalog: null
+Queries:
+  - Text: ""
+    Name: GetAuthor
+    Cmd: :one
+    Columns:
+      - Name: id
+        NotNull: true
+        IsArray: false
+        Comment: ""
+        Length: 0
+        IsNamedParam: false
+        IsFuncCall: false
+        Scope: ""
+        Table: null
+        TableAlias: ""
+        Type:
+          Catalog: ""
+          Schema: ""
+          Name: bigserial
+        IsSqlcSlice: false
+        EmbedTable: null
+        OriginalName: ""
+        Unsigned: false
+        ArrayDims: 0
+    Params: []
+    Comments: []
+    Filename: ""
+    InsertIntoTable: null
+SqlcVersion: v1.28.0
+PluginOptions:
+  filename: test.file
+GlobalOptions: null
+`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			out, err := code.DumpRequest(createDumpTestGenerateRequest(), testCase.format)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, string(out))
+		})
+	}
+}
+
+func TestDumpRequestProtobuf(t *testing.T) {
+	request := createDumpTestGenerateRequest()
+
+	out, err := code.DumpRequest(request, code.DumpFormatProtobuf)
+	assert.NoError(t, err)
+
+	decoded := &plugin.GenerateRequest{}
+	assert.NoError(t, proto.Unmarshal(out, decoded))
+	assert.True(t, proto.Equal(request, decoded))
+}
+
+func TestDumpRequestInvalidFormat(t *testing.T) {
+	_, err := code.DumpRequest(createDumpTestGenerateRequest(), "xml")
+	assert.ErrorContains(t, err, "invalid dump format \"xml\"")
+}
@@ -0,0 +1,382 @@
+package code
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// The formats of the `format` option used to post-process the generated files.
+const (
+	formatGo   = "go"
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatTOML = "toml"
+	// formatNone disables the format detected from the file extension.
+	formatNone = "none"
+)
+
+var formats = []string{formatGo, formatJSON, formatYAML, formatTOML, formatNone}
+
+// getFileFormat returns the format of a generated file, the `format` option value or, when the option is not
+// defined, the format detected from the file extension.
+func getFileFormat(option *string, filename string) string {
+	if option != nil {
+		return *option
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return formatJSON
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	default:
+		return formatNone
+	}
+}
+
+// formatContents post-processes the contents of a generated file according to its format.
+func formatContents(contentsFormat string, filename string, contents []byte) ([]byte, error) {
+	switch contentsFormat {
+	case formatNone:
+		return contents, nil
+	case formatGo:
+		return formatGoSource(filename, contents)
+	case formatJSON:
+		return formatJSONSource(filename, contents)
+	case formatYAML:
+		return formatYAMLSource(filename, contents)
+	case formatTOML:
+		return formatTOMLSource(filename, contents)
+	default:
+		return nil, fmt.Errorf("unknown format %q, must be one of: %q", contentsFormat, formats)
+	}
+}
+
+// formatGoSource removes the unused imports of a Go source file and formats it like `gofmt`.
+func formatGoSource(filename string, src []byte) ([]byte, error) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the file %q as Go code, %w", filename, describeGoError(err, src))
+	}
+
+	src = removeUnusedImports(fileSet, file, src)
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the file %q as Go code, %w", filename, describeGoError(err, src))
+	}
+
+	return formatted, nil
+}
+
+// describeGoError appends the source lines around the first Go syntax error to the error.
+func describeGoError(err error, src []byte) error {
+	var errs scanner.ErrorList
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return err
+	}
+
+	snippet, ok := sourceSnippet(string(src), errs[0].Pos.Line, errs[0].Pos.Column-1)
+	if !ok {
+		return err
+	}
+
+	return fmt.Errorf("%w%s", err, snippet)
+}
+
+// removeUnusedImports removes the import declarations whose package is never referenced in the file, similar to
+// `goimports`. The blank, dot and "C" imports are always kept.
+func removeUnusedImports(fileSet *token.FileSet, file *ast.File, src []byte) []byte {
+	used := map[string]bool{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+
+		return true
+	})
+
+	isUnused := func(spec *ast.ImportSpec) bool {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || importPath == "C" {
+			return false
+		}
+
+		name := importPathToName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		return name != "_" && name != "." && !used[name]
+	}
+
+	// ranges are the byte offsets of the source to remove, in source order.
+	ranges := [][2]int{}
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+
+		unused := []ast.Spec{}
+		for _, spec := range genDecl.Specs {
+			if isUnused(spec.(*ast.ImportSpec)) {
+				unused = append(unused, spec)
+			}
+		}
+
+		if len(unused) == len(genDecl.Specs) {
+			ranges = append(ranges, lineRange(fileSet, src, genDecl.Pos(), genDecl.End()))
+			continue
+		}
+
+		for _, spec := range unused {
+			ranges = append(ranges, lineRange(fileSet, src, spec.Pos(), spec.End()))
+		}
+	}
+
+	if len(ranges) == 0 {
+		return src
+	}
+
+	result := make([]byte, 0, len(src))
+	offset := 0
+	for _, r := range ranges {
+		result = append(result, src[offset:r[0]]...)
+		offset = r[1]
+	}
+
+	return append(result, src[offset:]...)
+}
+
+// lineRange returns the byte offsets of a node, extended to the whole line when nothing else than whitespace and
+// comments share the line with the node.
+func lineRange(fileSet *token.FileSet, src []byte, pos token.Pos, end token.Pos) [2]int {
+	start := fileSet.Position(pos).Offset
+	stop := fileSet.Position(end).Offset
+
+	lineStart := strings.LastIndexByte(string(src[:start]), '\n') + 1
+	if strings.TrimSpace(string(src[lineStart:start])) == "" {
+		start = lineStart
+	}
+
+	if lineEnd := strings.IndexByte(string(src[stop:]), '\n'); lineEnd >= 0 {
+		rest := strings.TrimSpace(string(src[stop : stop+lineEnd]))
+		if rest == "" || strings.HasPrefix(rest, "//") {
+			stop += lineEnd + 1
+		}
+	}
+
+	return [2]int{start, stop}
+}
+
+// importPathToName returns the package name assumed from an import path, following the `goimports` conventions:
+// "github.com/jackc/pgx/v5" is "pgx", "gopkg.in/yaml.v3" is "yaml" and "github.com/mattn/go-sqlite3" is "sqlite3".
+func importPathToName(importPath string) string {
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil {
+			dir := path.Dir(importPath)
+			if dir != "." {
+				base = path.Base(dir)
+			}
+		}
+	}
+
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexAny(base, ".-"); i >= 0 {
+		base = base[:i]
+	}
+
+	return base
+}
+
+// formatJSONSource validates a JSON file and indents it with 2 spaces, keeping the object keys order.
+func formatJSONSource(filename string, src []byte) ([]byte, error) {
+	buf := bytes.Buffer{}
+	if err := json.Indent(&buf, src, "", "  "); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// The offset is the number of bytes read, including the invalid character.
+			line, column := offsetPosition(src, int(syntaxErr.Offset)-1)
+			if snippet, ok := sourceSnippet(string(src), line, column); ok {
+				err = fmt.Errorf("%w%s", err, snippet)
+			}
+		}
+
+		return nil, fmt.Errorf("failed to format the file %q as JSON, %w", filename, err)
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// yamlErrorLine matches the line number of the YAML parse errors, formatted as "yaml: line <line>: <message>".
+var yamlErrorLine = regexp.MustCompile(`line (\d+):`)
+
+// formatYAMLSource validates a YAML file and indents it with 2 spaces, keeping the comments, the keys order and every
+// document of a multi-document file.
+func formatYAMLSource(filename string, src []byte) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	buf := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	documents := 0
+	for ; ; documents++ {
+		document := yaml.Node{}
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				line, _ := strconv.Atoi(match[1])
+				if snippet, ok := sourceSnippet(string(src), line, -1); ok {
+					err = fmt.Errorf("%w%s", err, snippet)
+				}
+			}
+
+			return nil, fmt.Errorf("failed to format the file %q as YAML, %w", filename, err)
+		}
+
+		if err := encoder.Encode(&document); err != nil {
+			return nil, fmt.Errorf("failed to format the file %q as YAML, %w", filename, err)
+		}
+	}
+
+	if documents == 0 {
+		return src, nil
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to format the file %q as YAML, %w", filename, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// formatTOMLSource validates a TOML file and removes the indentation of its keys, table headers and comments, keeping
+// the comments, the keys order and the multi-line strings and arrays as they are.
+func formatTOMLSource(filename string, src []byte) ([]byte, error) {
+	document := map[string]any{}
+	if err := toml.Unmarshal(src, &document); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, column := decodeErr.Position()
+			if snippet, ok := sourceSnippet(string(src), line, column-1); ok {
+				err = fmt.Errorf("%w%s", err, snippet)
+			}
+		}
+
+		return nil, fmt.Errorf("failed to format the file %q as TOML, %w", filename, err)
+	}
+
+	if len(bytes.TrimSpace(src)) == 0 {
+		return src, nil
+	}
+
+	buf := bytes.Buffer{}
+	scanner := tomlScanner{}
+	for _, line := range strings.Split(strings.TrimRight(string(src), "\n"), "\n") {
+		if !scanner.inValue() {
+			line = strings.TrimLeft(line, " \t")
+		}
+
+		scanner.scanLine(line)
+		if scanner.multilineString == "" {
+			line = strings.TrimRight(line, " \t\r")
+		}
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// tomlScanner tracks, line by line, whether a valid TOML document is inside a multi-line string or array.
+type tomlScanner struct {
+	// multilineString is the delimiter (`"""` or `'''`) of the multi-line string being scanned.
+	multilineString string
+	// arrayDepth is the number of open array brackets.
+	arrayDepth int
+}
+
+// inValue returns true when the next line continues a multi-line string or array value.
+func (s *tomlScanner) inValue() bool {
+	return s.multilineString != "" || s.arrayDepth > 0
+}
+
+func (s *tomlScanner) scanLine(line string) {
+	for i := 0; i < len(line); i++ {
+		if s.multilineString != "" {
+			switch {
+			case line[i] == '\\' && s.multilineString == `"""`:
+				i++
+			case strings.HasPrefix(line[i:], s.multilineString):
+				// Up to 2 quotes can precede the closing delimiter.
+				for i+3 < len(line) && line[i+3] == s.multilineString[0] {
+					i++
+				}
+				i += 2
+				s.multilineString = ""
+			}
+
+			continue
+		}
+
+		switch line[i] {
+		case '#':
+			return
+		case '"', '\'':
+			if delimiter := strings.Repeat(string(line[i]), 3); strings.HasPrefix(line[i:], delimiter) {
+				s.multilineString = delimiter
+				i += 2
+
+				continue
+			}
+
+			// Single line strings, only the basic (double quoted) strings have escapes.
+			quote := line[i]
+			for i++; i < len(line) && line[i] != quote; i++ {
+				if quote == '"' && line[i] == '\\' {
+					i++
+				}
+			}
+		case '[':
+			s.arrayDepth++
+		case ']':
+			s.arrayDepth--
+		}
+	}
+}
+
+// offsetPosition returns the 1-based line and the 0-based column of a byte offset of a source.
+func offsetPosition(src []byte, offset int) (int, int) {
+	offset = min(max(offset, 0), len(src))
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+
+	return bytes.Count(src[:offset], []byte("\n")) + 1, offset - lineStart
+}
@@ -0,0 +1,300 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestFormatGo(t *testing.T) {
+	testCases := map[string]struct {
+		template         string
+		expectedContents string
+	}{
+		"gofmt": {
+			template: "package db\nfunc   Get( id int64 )(string,error){\nreturn \"\",nil}\n",
+			expectedContents: `package db
+
+func Get(id int64) (string, error) {
+	return "", nil
+}
+`,
+		},
+		"remove unused imports": {
+			template: `package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt" // fmt is unused
+	_ "embed"
+	. "strings"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"gopkg.in/yaml.v3"
+)
+
+func Get(ctx context.Context, conn *pgx.Conn) (sql.NullString, pgtype.Text) {
+	return sql.NullString{}, pgtype.Text{}
+}
+`,
+			expectedContents: `package db
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	. "strings"
+)
+
+func Get(ctx context.Context, conn *pgx.Conn) (sql.NullString, pgtype.Text) {
+	return sql.NullString{}, pgtype.Text{}
+}
+`,
+		},
+		"remove every import": {
+			template: "package db\n\nimport \"fmt\"\n\nimport (\n\t\"time\"\n)\n\nconst name = \"db\"\n",
+			expectedContents: `package db
+
+const name = "db"
+`,
+		},
+		"versioned import path": {
+			template: "package db\n\nimport (\n\t\"github.com/jackc/pgx/v5\"\n\t\"github.com/mattn/go-sqlite3\"\n)\n\n" +
+				"var _ = pgx.Identifier{}\nvar _ = sqlite3.SQLiteConn{}\n",
+			expectedContents: `package db
+
+import (
+	"github.com/jackc/pgx/v5"
+	"github.com/mattn/go-sqlite3"
+)
+
+var _ = pgx.Identifier{}
+var _ = sqlite3.SQLiteConn{}
+`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			response, err := code.Generate(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "db.go",
+					"format": "go",
+					"template": "` + jsonString(testCase.template) + `"
+				}`),
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, testCase.expectedContents, string(response.Files[0].Contents))
+		})
+	}
+}
+
+func TestFormatStructuredData(t *testing.T) {
+	testCases := map[string]struct {
+		filename         string
+		format           string
+		template         string
+		expectedContents string
+	}{
+		"json extension": {
+			filename: "queries.json",
+			template: `{"queries": [{"name": "GetAuthor", "params": []}, {"name": "ListAuthors"}], "count": 2}`,
+			expectedContents: "{\n  \"queries\": [\n    {\n      \"name\": \"GetAuthor\",\n      \"params\": []\n    },\n" +
+				"    {\n      \"name\": \"ListAuthors\"\n    }\n  ],\n  \"count\": 2\n}\n",
+		},
+		"yaml extension": {
+			filename: "queries.yaml",
+			template: "# The queries.\nqueries:\n    - name: GetAuthor # :one\n      text: |\n        SELECT *\n        FROM authors\n",
+			expectedContents: `# The queries.
+queries:
+  - name: GetAuthor # :one
+    text: |
+      SELECT *
+      FROM authors
+`,
+		},
+		"yml extension with several documents": {
+			filename:         "queries.YML",
+			template:         "name: GetAuthor\n---\nname:    ListAuthors\n",
+			expectedContents: "name: GetAuthor\n---\nname: ListAuthors\n",
+		},
+		"yaml empty file": {
+			filename:         "queries.yaml",
+			template:         "",
+			expectedContents: "",
+		},
+		"json format option": {
+			filename:         "queries.txt",
+			format:           "json",
+			template:         `[1,2]`,
+			expectedContents: "[\n  1,\n  2\n]\n",
+		},
+		"yaml format option": {
+			filename:         "queries",
+			format:           "yaml",
+			template:         `{name: GetAuthor}`,
+			expectedContents: "{name: GetAuthor}\n",
+		},
+		"none format option": {
+			filename:         "queries.json",
+			format:           "none",
+			template:         `{"invalid": }`,
+			expectedContents: `{"invalid": }`,
+		},
+		"toml extension": {
+			filename: "queries.toml",
+			template: `# The queries.
+  [queries.GetAuthor] # :one
+    cmd = ':one'
+    text = """
+  SELECT * # [
+  """
+    params = [
+      "id", # [
+    ]
+    note = "[ # '''"
+    quote = '[ # "'
+
+  [[tags]]
+    name = 'authors'
+`,
+			expectedContents: `# The queries.
+[queries.GetAuthor] # :one
+cmd = ':one'
+text = """
+  SELECT * # [
+  """
+params = [
+      "id", # [
+    ]
+note = "[ # '''"
+quote = '[ # "'
+
+[[tags]]
+name = 'authors'
+`,
+		},
+		"toml format option": {
+			filename:         "queries.conf",
+			format:           "toml",
+			template:         "  name = 'GetAuthor'",
+			expectedContents: "name = 'GetAuthor'\n",
+		},
+		"toml empty file": {
+			filename:         "queries.toml",
+			template:         "",
+			expectedContents: "",
+		},
+		"unknown extension": {
+			filename:         "queries.ini",
+			template:         `queries = [ "GetAuthor" ]`,
+			expectedContents: `queries = [ "GetAuthor" ]`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			format := ""
+			if testCase.format != "" {
+				format = `"format": "` + testCase.format + `",`
+			}
+
+			response, err := code.Generate(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "` + testCase.filename + `",
+					` + format + `
+					"template": "` + jsonString(testCase.template) + `"
+				}`),
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, testCase.expectedContents, string(response.Files[0].Contents))
+		})
+	}
+}
+
+func TestFormatFailure(t *testing.T) {
+	testCases := map[string]struct {
+		options        string
+		expectedErrMsg string
+	}{
+		"invalid format": {
+			options:        `{"filename": "db.go", "format": "rust", "template": ""}`,
+			expectedErrMsg: `invalid sqlc config 'sql[].codegen.options.format' field value "rust", must be one of: ["go" "json" "yaml" "toml" "none"]`,
+		},
+		"invalid output format": {
+			options:        `{"outputs": [{"filename": "db.go", "format": "", "template": ""}]}`,
+			expectedErrMsg: `invalid sqlc config 'sql[].codegen.options.outputs[0].format' field value ""`,
+		},
+		"go syntax error": {
+			options: `{
+				"filename": "db.go",
+				"format": "go",
+				"template": "package db\n\nfunc Get() {\n\treturn 1 +\n}\n"
+			}`,
+			expectedErrMsg: `failed to format the file "db.go" as Go code, db.go:5:1: expected operand, found '}'
+  3 | func Get() {
+  4 | 	return 1 +
+> 5 | }
+    | ^`,
+		},
+		"json syntax error": {
+			options: `{
+				"filename": "queries.json",
+				"template": "{\n  \"name\": \"GetAuthor\",\n}\n"
+			}`,
+			expectedErrMsg: `failed to format the file "queries.json" as JSON, invalid character '}' looking for beginning of ` +
+				`object key string
+  1 | {
+  2 |   "name": "GetAuthor",
+> 3 | }
+    | ^`,
+		},
+		"yaml syntax error": {
+			options: `{
+				"filename": "queries.yaml",
+				"template": "queries:\n  - name: GetAuthor\n   text: SELECT 1\n"
+			}`,
+			expectedErrMsg: `failed to format the file "queries.yaml" as YAML, yaml: line 1: did not find expected '-' ` +
+				`indicator
+> 1 | queries:
+  2 |   - name: GetAuthor
+  3 |    text: SELECT 1`,
+		},
+		"toml syntax error": {
+			options: `{
+				"filename": "queries.toml",
+				"template": "[queries]\nname = 'GetAuthor'\ntext = SELECT\n"
+			}`,
+			expectedErrMsg: `failed to format the file "queries.toml" as TOML, toml: incomplete number
+  1 | [queries]
+  2 | name = 'GetAuthor'
+> 3 | text = SELECT
+    |        ^`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			_, err := code.Generate(&plugin.GenerateRequest{PluginOptions: []byte(testCase.options)})
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
@@ -2,9 +2,11 @@ package code
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"strings"
 	"text/template"
 
 	"google.golang.org/protobuf/proto"
@@ -12,53 +14,276 @@ import (
 	"github.com/NMFR/sqlc-template/internal/protos/plugin"
 )
 
-type pluginOptions struct {
-	Filename *string `json:"filename,omitempty"`
-	Template *string `json:"template,omitempty"`
+// reservedTemplateNames are the names of the templates defined by the plugin that cannot be used as partial names.
+var reservedTemplateNames = []string{"filename", "template"}
+
+// parsePartials returns a template set containing every partial template, the partial templates are parsed in name
+// order so that the errors are deterministic.
+func parsePartials(
+	funcMap template.FuncMap, partials map[string]string, strict bool, limits *limits,
+) (*template.Template, error) {
+	tmpl := template.New("partials").Funcs(funcMap)
+	if strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+
+	names := make([]string, 0, len(partials))
+	for name := range partials {
+		if slices.Contains(reservedTemplateNames, name) {
+			return nil, fmt.Errorf("the partial template name %q is reserved", name)
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if _, err := tmpl.New(name).Parse(partials[name]); err != nil {
+			return nil, fmt.Errorf(
+				"failed to parse the %q partial template, %w", name, describeTemplateError(err, partials),
+			)
+		}
+	}
+	limits.limitTemplate(tmpl)
+
+	return tmpl, nil
+}
+
+// parseTemplate parses a new template that has access to every template defined in the partials template set.
+// The partials template set is cloned so that the `define` blocks of one template do not leak into another.
+func parseTemplate(partials *template.Template, name string, text string, limits *limits) (*template.Template, error) {
+	tmpl, err := partials.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err = tmpl.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	limits.limitTemplate(tmpl)
+
+	return tmpl, nil
+}
+
+// strictModeValues are the values printed by the templates when a missing or nil value is printed, these values are
+// rejected in strict mode.
+var strictModeValues = []string{"<no value>", "<nil>"}
+
+// generator generates the files of a request.
+type generator struct {
+	request *plugin.GenerateRequest
+	options *pluginOptions
+	// templateRoot is the directory the relative template file paths are resolved from.
+	templateRoot string
+	partials     *template.Template
+	limits       *limits
+	// sources maps the name of every partial template to its text.
+	sources map[string]string
+	// filenames contains the names of every file generated so far.
+	filenames map[string]bool
+}
+
+func (g *generator) executeTemplate(tmpl *template.Template, data any, sources map[string]string) ([]byte, error) {
+	buf := limitedBuffer{limits: g.limits}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		err = describeTemplateError(err, sources)
+		if g.options.Strict && strings.Contains(err.Error(), "nil pointer evaluating") {
+			return nil, fmt.Errorf(
+				"strict mode: a field of a nil value was accessed, check the value with `if` or `with` first, %w", err,
+			)
+		}
+
+		return nil, err
+	}
+
+	if g.options.Strict {
+		for _, value := range strictModeValues {
+			if bytes.Contains(buf.Bytes(), []byte(value)) {
+				return nil, fmt.Errorf(
+					"strict mode: the template printed %q, a missing or nil value was printed, check the field names", value,
+				)
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// generateOutput generates the files of a single output.
+func (g *generator) generateOutput(options *outputOptions) ([]*plugin.File, error) {
+	templateData, err := getTemplateData(g.request, options.getMode(), options.getGroupBy(), g.options.Strict)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := options.getTemplate(g.templateRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := maps.Clone(g.sources)
+	sources["filename"] = *options.Filename
+	sources["template"] = text
+
+	filenameTmpl, err := parseTemplate(g.partials, "filename", *options.Filename, g.limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the filename template, %w", describeTemplateError(err, sources))
+	}
+
+	tmpl, err := parseTemplate(g.partials, "template", text, g.limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the template, %w", describeTemplateError(err, sources))
+	}
+
+	// The header template is executed with its own sources so that it does not hide a partial named "header".
+	var headerTmpl *template.Template
+	headerSources := maps.Clone(g.sources)
+	if options.Header != nil && options.Header.enabled {
+		headerSources["header"] = options.Header.template
+		headerTmpl, err = parseTemplate(g.partials, "header", options.Header.template, g.limits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the header template, %w", describeTemplateError(err, headerSources))
+		}
+	}
+
+	files := make([]*plugin.File, 0, len(templateData))
+	for _, data := range templateData {
+		// The template data description is only defined in the fan-out modes.
+		description := ""
+		if name := describeTemplateData(data); name != "" {
+			description = " for the " + name
+		}
+
+		filename, err := g.executeTemplate(filenameTmpl, data, sources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute the filename template%s, %w", description, err)
+		}
+
+		if g.filenames[string(filename)] {
+			return nil, fmt.Errorf("the filename template generated the duplicate file name %q", filename)
+		}
+		g.filenames[string(filename)] = true
+		if err := g.limits.checkFiles(len(g.filenames)); err != nil {
+			return nil, err
+		}
+
+		contents, err := g.executeTemplate(tmpl, data, sources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute the template%s, %w", description, err)
+		}
+
+		if headerTmpl != nil {
+			headerData := getHeaderTemplateData(g.request, data, string(filename))
+			header, err := g.executeTemplate(headerTmpl, headerData, headerSources)
+			if err != nil {
+				return nil, fmt.Errorf("failed to execute the header template%s, %w", description, err)
+			}
+
+			comment, err := commentHeader(options.path, string(filename), string(header))
+			if err != nil {
+				return nil, err
+			}
+			contents = append([]byte(comment), contents...)
+		}
+
+		contents, err = formatContents(getFileFormat(options.Format, string(filename)), string(filename), contents)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, &plugin.File{Name: string(filename), Contents: contents})
+	}
+
+	return files, nil
 }
 
 func Generate(request *plugin.GenerateRequest) (*plugin.GenerateResponse, error) {
-	pluginOptions := &pluginOptions{}
-	if err := json.Unmarshal(request.GetPluginOptions(), pluginOptions); err != nil {
-		return nil, fmt.Errorf("failed to parse the sqlc config 'sql[].codegen.options' field to JSON, %w", err)
+	return GenerateWithConfig(request, Config{})
+}
+
+// GenerateWithConfig generates the files of a request with the plugin settings that are not part of the request.
+func GenerateWithConfig(request *plugin.GenerateRequest, config Config) (*plugin.GenerateResponse, error) {
+	pluginOptions, err := parsePluginOptions(request.GetPluginOptions())
+	if err != nil {
+		return nil, err
 	}
 
-	if pluginOptions.Filename == nil {
-		return nil, fmt.Errorf("missing the sqlc config 'sql[].codegen.options.filename' field")
+	outputs, err := pluginOptions.getOutputs()
+	if err != nil {
+		return nil, err
+	}
+
+	templateRoot := pluginOptions.getTemplateRoot(config.TemplateRoot)
+	partialTemplates, err := pluginOptions.getPartials(templateRoot)
+	if err != nil {
+		return nil, err
 	}
 
-	if pluginOptions.Template == nil {
-		return nil, fmt.Errorf("missing the sqlc 'sql[].codegen.options.template' field")
+	limits, err := pluginOptions.Limits.getLimits(optionsPath + ".limits")
+	if err != nil {
+		return nil, err
 	}
 
-	tmpl, err := template.
-		New("template").
-		Funcs(getTemplateFunctions()).
-		Parse(*pluginOptions.Template)
+	funcMap := getTemplateFunctions(request, pluginOptions)
+	limits.limitFunctions(funcMap)
+
+	partials, err := parsePartials(funcMap, partialTemplates, pluginOptions.Strict, limits)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse the template, %w", err)
+		return nil, err
 	}
 
-	buf := bytes.Buffer{}
-	if err := tmpl.Execute(&buf, request); err != nil {
-		return nil, fmt.Errorf("failed to execute the template, %w", err)
+	generator := &generator{
+		request:      request,
+		options:      pluginOptions,
+		templateRoot: templateRoot,
+		partials:     partials,
+		limits:       limits,
+		sources:      partialTemplates,
+		filenames:    map[string]bool{},
+	}
+
+	response := &plugin.GenerateResponse{}
+	for _, output := range outputs {
+		files, err := generator.generateOutput(output)
+		if err != nil {
+			if output.path != optionsPath {
+				return nil, fmt.Errorf("failed to generate the sqlc config '%s' output, %w", output.path, err)
+			}
+
+			return nil, err
+		}
+
+		response.Files = append(response.Files, files...)
+	}
+
+	if pluginOptions.DebugDump != nil {
+		format, err := getDumpFormat(*pluginOptions.DebugDump)
+		if err != nil {
+			return nil, err
+		}
+
+		if generator.filenames[*pluginOptions.DebugDump] {
+			return nil, fmt.Errorf("the debug dump file name %q is already generated by a template", *pluginOptions.DebugDump)
+		}
+
+		contents, err := DumpRequest(request, format)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Files = append(response.Files, &plugin.File{Name: *pluginOptions.DebugDump, Contents: contents})
 	}
 
-	return &plugin.GenerateResponse{
-			Files: []*plugin.File{
-				{Name: *pluginOptions.Filename, Contents: buf.Bytes()},
-			},
-		},
-		nil
+	return response, nil
 }
 
-func GenerateFromBytes(in []byte) ([]byte, error) {
+func GenerateFromBytes(in []byte, config Config) ([]byte, error) {
 	request := &plugin.GenerateRequest{}
 	if err := proto.Unmarshal(in, request); err != nil {
 		return nil, fmt.Errorf("failed to parse / unmarshal the sqlc plugin GenerateRequest, %w", err)
 	}
 
-	response, err := Generate(request)
+	response, err := GenerateWithConfig(request, config)
 	if err != nil {
 		return nil, err
 	}
@@ -71,13 +296,13 @@ func GenerateFromBytes(in []byte) ([]byte, error) {
 	return out, nil
 }
 
-func GenerateFromReader(reader io.Reader, writer io.Writer) error {
+func GenerateFromReader(reader io.Reader, writer io.Writer, config Config) error {
 	in, err := io.ReadAll(reader)
 	if err != nil {
 		return fmt.Errorf("failed to read from stream, %w", err)
 	}
 
-	out, err := GenerateFromBytes(in)
+	out, err := GenerateFromBytes(in, config)
 	if err != nil {
 		return err
 	}
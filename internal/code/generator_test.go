@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -167,6 +169,310 @@ func TestCodeGeneratorSuccess(t *testing.T) {
 				},
 			},
 		},
+		"per-query-mode": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				Queries: []*plugin.Query{
+					{Name: "GetAuthor", Cmd: ":one"},
+					{Name: "ListAuthors", Cmd: ":many"},
+				},
+				PluginOptions: []byte(`{
+					"mode": "per-query",
+					"filename": "{{ .Name | ToSnake }}.go",
+					"template": "{{ .Name }} {{ .Cmd }} {{ .Request.SqlcVersion }} {{ len .Request.Queries }}"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "get_author.go", Contents: []byte("GetAuthor :one v1.28.0 2")},
+					{Name: "list_authors.go", Contents: []byte("ListAuthors :many v1.28.0 2")},
+				},
+			},
+		},
+		"per-query-mode-without-queries": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"mode": "per-query",
+					"filename": "{{ .Name }}.go",
+					"template": "{{ .Name }}"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{},
+		},
+		"per-table-mode": {
+			request: &plugin.GenerateRequest{
+				Catalog: &plugin.Catalog{
+					Schemas: []*plugin.Schema{
+						{
+							Name: "public",
+							Tables: []*plugin.Table{
+								{
+									Rel:     &plugin.Identifier{Schema: "public", Name: "authors"},
+									Columns: []*plugin.Column{{Name: "id"}, {Name: "name"}},
+								},
+								{
+									Rel:     &plugin.Identifier{Schema: "public", Name: "books"},
+									Columns: []*plugin.Column{{Name: "id"}},
+								},
+							},
+						},
+						{
+							Name: "audit",
+							Tables: []*plugin.Table{
+								{
+									Rel:     &plugin.Identifier{Schema: "audit", Name: "authors"},
+									Columns: []*plugin.Column{{Name: "id"}},
+								},
+							},
+						},
+					},
+				},
+				PluginOptions: []byte(`{
+					"mode": "per-table",
+					"filename": "{{ .Schema.Name }}/{{ .Rel.Name | ToCamel }}.kt",
+					"template": "{{ range .Columns }}{{ .Name }};{{ end }}{{ len .Request.Catalog.Schemas }}"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "public/Authors.kt", Contents: []byte("id;name;2")},
+					{Name: "public/Books.kt", Contents: []byte("id;2")},
+					{Name: "audit/Authors.kt", Contents: []byte("id;2")},
+				},
+			},
+		},
+		"per-enum-mode": {
+			request: &plugin.GenerateRequest{
+				Catalog: &plugin.Catalog{
+					Schemas: []*plugin.Schema{
+						{
+							Name: "public",
+							Enums: []*plugin.Enum{
+								{Name: "book_type", Vals: []string{"FICTION", "NONFICTION"}},
+								{Name: "status", Vals: []string{"open"}},
+							},
+						},
+					},
+				},
+				PluginOptions: []byte(`{
+					"mode": "per-enum",
+					"filename": "{{ .Name | ToCamel }}.java",
+					"template": "{{ .Schema.Name }}.{{ .Name }}: {{ join \",\" .Vals }}"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "BookType.java", Contents: []byte("public.book_type: FICTION,NONFICTION")},
+					{Name: "Status.java", Contents: []byte("public.status: open")},
+				},
+			},
+		},
+		"per-table-mode-without-catalog": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"mode": "per-table",
+					"filename": "{{ .Rel.Name }}.kt",
+					"template": ""
+				}`),
+			},
+			expected: &plugin.GenerateResponse{},
+		},
+		"group-by-query-file": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				Queries: []*plugin.Query{
+					{Name: "GetAuthor", Filename: "authors.sql"},
+					{Name: "GetBook", Filename: "books.sql"},
+					{Name: "ListAuthors", Filename: "authors.sql"},
+				},
+				PluginOptions: []byte(`{
+					"group_by": "query_file",
+					"filename": "{{ .Filename }}.ts",
+					"template": "{{ .SqlcVersion }}{{ range .Queries }} {{ .Name }}{{ end }} {{ len .Request.Queries }}"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "authors.sql.ts", Contents: []byte("v1.28.0 GetAuthor ListAuthors 3")},
+					{Name: "books.sql.ts", Contents: []byte("v1.28.0 GetBook 3")},
+				},
+			},
+		},
+		"outputs": {
+			request: &plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor"}, {Name: "ListAuthors"}},
+				PluginOptions: []byte(`{
+					"outputs": [
+						{
+							"filename": "models.go",
+							"template": "models"
+						},
+						{
+							"mode": "per-query",
+							"filename": "{{ .Name | ToSnake }}.go",
+							"template": "{{ .Name }}"
+						},
+						{
+							"filename": "mock.go",
+							"template": "{{ len .Queries }} mocks"
+						}
+					]
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "models.go", Contents: []byte("models")},
+					{Name: "get_author.go", Contents: []byte("GetAuthor")},
+					{Name: "list_authors.go", Contents: []byte("ListAuthors")},
+					{Name: "mock.go", Contents: []byte("2 mocks")},
+				},
+			},
+		},
+		"root-output-and-outputs": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "root.go",
+					"template": "root",
+					"outputs": [
+						{
+							"filename": "other.go",
+							"template": "other"
+						}
+					]
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "root.go", Contents: []byte("root")},
+					{Name: "other.go", Contents: []byte("other")},
+				},
+			},
+		},
+		"partials": {
+			request: &plugin.GenerateRequest{
+				Queries: []*plugin.Query{
+					{
+						Name: "GetAuthor",
+						Columns: []*plugin.Column{
+							{Name: "id", Type: &plugin.Identifier{Name: "bigserial"}},
+							{Name: "bio", Type: &plugin.Identifier{Name: "text"}},
+						},
+					},
+				},
+				PluginOptions: []byte(`{
+					"partials": {
+						"goType": "{{ if eq .Type.Name \"text\" }}string{{ else }}int64{{ end }}",
+						"helpers": "{{ define \"fieldName\" }}{{ .Name | ToCamel }}{{ end }}"
+					},
+					"filename": "{{ template \"fieldName\" (index .Queries 0) }}.go",
+					"template": "{{ range .Queries }}{{ range .Columns }}{{ template \"fieldName\" . }} {{ template \"goType\" . }};{{ end }}{{ end }}",
+					"outputs": [
+						{
+							"mode": "per-query",
+							"filename": "{{ .Name }}.types",
+							"template": "{{ range .Columns }}{{ template \"goType\" . }};{{ end }}"
+						}
+					]
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "GetAuthor.go", Contents: []byte("Id int64;Bio string;")},
+					{Name: "GetAuthor.types", Contents: []byte("int64;string;")},
+				},
+			},
+		},
+		"debug-dump": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				PluginOptions: []byte(`{
+					"filename": "version.txt",
+					"template": "{{ .SqlcVersion }}",
+					"debug_dump": "request.yaml"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "version.txt", Contents: []byte("v1.28.0")},
+					{
+						Name: "request.yaml",
+						Contents: []byte(`Settings: null
+Catalog: null
+Queries: []
+SqlcVersion: v1.28.0
+PluginOptions:
+  debug_dump: request.yaml
+  filename: version.txt
+  template: '{{ .SqlcVersion }}'
+GlobalOptions: null
+`),
+					},
+				},
+			},
+		},
+		"debug-dump-only": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{"debug_dump": "request.json"}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{
+						Name: "request.json",
+						Contents: []byte(`{
+  "Settings": null,
+  "Catalog": null,
+  "Queries": [],
+  "SqlcVersion": "",
+  "PluginOptions": {
+    "debug_dump": "request.json"
+  },
+  "GlobalOptions": null
+}
+`),
+					},
+				},
+			},
+		},
+		"strict-mode": {
+			request: &plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor", Columns: []*plugin.Column{{Name: "id"}}}},
+				PluginOptions: []byte(`{
+					"strict": true,
+					"filename": "{{ (index .Queries 0).Name }}.go",
+					"template": "{{ $d := dict \"a\" 1 }}{{ $d.a }}{{ range .Queries }}{{ range .Columns }}{{ with .Table }}{{ .Name }}{{ end }}{{ end }}{{ end }}"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{{Name: "GetAuthor.go", Contents: []byte("1")}},
+			},
+		},
+		"non-strict-mode-missing-values": {
+			request: &plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor", Columns: []*plugin.Column{{Name: "id"}}}},
+				PluginOptions: []byte(`{
+					"filename": "test.file",
+					"template": "{{ $d := dict \"a\" 1 }}{{ $d.b }} {{ (index (index .Queries 0).Columns 0).Table }}"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{{Name: "test.file", Contents: []byte("<no value> <nil>")}},
+			},
+		},
+		"single-mode-filename-template": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				PluginOptions: []byte(`{
+					"mode": "single",
+					"filename": "queries-{{ .SqlcVersion }}.yaml",
+					"template": "version: {{ .SqlcVersion }}"
+				}`),
+			},
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "queries-v1.28.0.yaml", Contents: []byte("version: v1.28.0\n")},
+				},
+			},
+		},
 	}
 
 	for testName, testCase := range testCases {
@@ -177,7 +483,7 @@ func TestCodeGeneratorSuccess(t *testing.T) {
 			assert.NoError(t, err)
 
 			responseBuffer := &bytes.Buffer{}
-			err = code.GenerateFromReader(requestReader, responseBuffer)
+			err = code.GenerateFromReader(requestReader, responseBuffer, code.Config{})
 			assert.NoError(t, err)
 
 			response, err := responseFromReader(responseBuffer)
@@ -271,16 +577,403 @@ func TestCodeGeneratorFailure(t *testing.T) {
 			response:       &bytes.Buffer{},
 			expectedErrMsg: "failed to execute the template",
 		},
+		"invalid mode option": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"mode": "per-everything",
+					"filename": "test.file",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.mode' field value \"per-everything\"",
+		},
+		"invalid group_by option": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"group_by": "table",
+					"filename": "test.file",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.group_by' field value \"table\"",
+		},
+		"group_by option with a fan-out mode": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"mode": "per-query",
+					"group_by": "query_file",
+					"filename": "test.file",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "the sqlc config 'sql[].codegen.options.group_by' field can only be used with the \"single\" mode",
+		},
+		"outputs option with invalid group_by option": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"outputs": [
+						{"filename": "a.file", "template": ""},
+						{"filename": "b.file", "template": "", "group_by": "file"}
+					]
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.outputs[1].group_by' field value \"file\"",
+		},
+		"outputs option with invalid mode option": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"outputs": [
+						{"filename": "a.file", "template": "", "mode": "per-column"}
+					]
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.outputs[0].mode' field value \"per-column\"",
+		},
+		"outputs option missing filename": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"outputs": [
+						{"filename": "a.file", "template": ""},
+						{"template": ""}
+					]
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "missing the sqlc config 'sql[].codegen.options.outputs[1].filename' field",
+		},
+		"outputs option missing template": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"outputs": [
+						{"filename": "a.file"}
+					]
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "missing the sqlc 'sql[].codegen.options.outputs[0].template' field",
+		},
+		"outputs option with invalid template": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"outputs": [
+						{"filename": "a.file", "template": "{{ invalid"}
+					]
+				}`),
+			}),
+			response: &bytes.Buffer{},
+			expectedErrMsg: "failed to generate the sqlc config 'sql[].codegen.options.outputs[0]' output, " +
+				"failed to parse the template",
+		},
+		"outputs option with duplicate filenames": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "a.file",
+					"template": "",
+					"outputs": [
+						{"filename": "a.file", "template": ""}
+					]
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "the filename template generated the duplicate file name \"a.file\"",
+		},
+		"invalid partials option template": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"partials": {"broken": "{{ invalid"},
+					"filename": "test.file",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "failed to parse the \"broken\" partial template",
+		},
+		"reserved partials option name": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"partials": {"template": ""},
+					"filename": "test.file",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "the partial template name \"template\" is reserved",
+		},
+		"define blocks do not leak between outputs": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"outputs": [
+						{"filename": "a.file", "template": "{{ define \"local\" }}{{ end }}"},
+						{"filename": "b.file", "template": "{{ template \"local\" }}"}
+					]
+				}`),
+			}),
+			response: &bytes.Buffer{},
+			expectedErrMsg: "failed to generate the sqlc config 'sql[].codegen.options.outputs[1]' output, " +
+				"failed to execute the template",
+		},
+		"invalid debug_dump option extension": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{"debug_dump": "request.xml"}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.debug_dump' field file extension \".xml\"",
+		},
+		"debug_dump option with a duplicate filename": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "request.json",
+					"template": "{}",
+					"debug_dump": "request.json"
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "the debug dump file name \"request.json\" is already generated by a template",
+		},
+		"strict mode missing map key": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"strict": true,
+					"filename": "test.file",
+					"template": "{{ $d := dict \"a\" 1 }}{{ $d.b }}"
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "map has no entry for key \"b\"",
+		},
+		"strict mode printed nil value": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor"}},
+				PluginOptions: []byte(`{
+					"strict": true,
+					"filename": "test.file",
+					"template": "{{ (index .Queries 0).InsertIntoTable }}"
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "strict mode: the template printed \"<nil>\"",
+		},
+		"strict mode printed no value in filename": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"strict": true,
+					"filename": "{{ $d := dict }}{{ index $d \"a\" }}",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "failed to execute the filename template, strict mode: the template printed \"<no value>\"",
+		},
+		"strict mode nil identifier": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor", Columns: []*plugin.Column{{Name: "id"}}}},
+				PluginOptions: []byte(`{
+					"strict": true,
+					"filename": "test.file",
+					"template": "{{ range .Queries }}{{ range .Columns }}{{ .Table.Name }}{{ end }}{{ end }}"
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "strict mode: a field of a nil value was accessed",
+		},
+		"invalid filename option template": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "{{ invalid",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "failed to parse the filename template",
+		},
+		"filename option template using non existant data": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "{{ .invalid }}",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "failed to execute the filename template",
+		},
+		"per-query mode with duplicate filenames": {
+			requestReader: requestToReaderNoErr(&plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor"}, {Name: "ListAuthors"}},
+				PluginOptions: []byte(`{
+					"mode": "per-query",
+					"filename": "queries.go",
+					"template": ""
+				}`),
+			}),
+			response:       &bytes.Buffer{},
+			expectedErrMsg: "the filename template generated the duplicate file name \"queries.go\"",
+		},
 	}
 
 	for testName, testCase := range testCases {
 		testCase := testCase
 
 		t.Run(testName, func(t *testing.T) {
-			err := code.GenerateFromReader(testCase.requestReader, testCase.response)
+			err := code.GenerateFromReader(testCase.requestReader, testCase.response, code.Config{})
 
 			assert.Error(t, err)
 			assert.ErrorContains(t, err, testCase.expectedErrMsg)
 		})
 	}
 }
+
+func TestCodeGeneratorTemplateFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"query.tmpl":             `{{ template "header.tmpl" . }}{{ range .Queries }} {{ .Name }}{{ end }}`,
+		"partials/header.tmpl":   `// sqlc {{ .SqlcVersion }}`,
+		"partials/field.tmpl":    `{{ define "field" }}{{ .Name | ToCamel }}{{ end }}`,
+		"partials/ignored.other": `{{ invalid`,
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	}
+
+	testCases := map[string]struct {
+		options             string
+		defaultTemplateRoot string
+		expected            *plugin.GenerateResponse
+		expectedErrMsg      string
+	}{
+		"template file": {
+			options: `{
+				"template_dir": "` + jsonString(filepath.Join(dir, "partials", "*.tmpl")) + `",
+				"filename": "queries.go",
+				"template_file": "` + jsonString(filepath.Join(dir, "query.tmpl")) + `",
+				"outputs": [
+					{
+						"mode": "per-query",
+						"filename": "{{ template \"field\" . }}.go",
+						"template": "{{ template \"header.tmpl\" .Request }}"
+					}
+				]
+			}`,
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "queries.go", Contents: []byte("// sqlc v1.28.0 GetAuthor")},
+					{Name: "GetAuthor.go", Contents: []byte("// sqlc v1.28.0")},
+				},
+			},
+		},
+		"template root option": {
+			options: `{
+				"template_root": "` + jsonString(dir) + `",
+				"template_dir": "partials/*.tmpl",
+				"filename": "queries.go",
+				"template_file": "query.tmpl"
+			}`,
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{{Name: "queries.go", Contents: []byte("// sqlc v1.28.0 GetAuthor")}},
+			},
+		},
+		"default template root": {
+			options: `{
+				"template_dir": "partials/*.tmpl",
+				"outputs": [
+					{"filename": "queries.go", "template_file": "query.tmpl"},
+					{"filename": "absolute.go", "template_file": "` + jsonString(filepath.Join(dir, "query.tmpl")) + `"}
+				]
+			}`,
+			defaultTemplateRoot: dir,
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{
+					{Name: "queries.go", Contents: []byte("// sqlc v1.28.0 GetAuthor")},
+					{Name: "absolute.go", Contents: []byte("// sqlc v1.28.0 GetAuthor")},
+				},
+			},
+		},
+		"template root option overrides the default template root": {
+			options: `{
+				"template_root": "` + jsonString(filepath.Join(dir, "partials")) + `",
+				"filename": "queries.go",
+				"template_file": "header.tmpl"
+			}`,
+			defaultTemplateRoot: filepath.Join(dir, "missing"),
+			expected: &plugin.GenerateResponse{
+				Files: []*plugin.File{{Name: "queries.go", Contents: []byte("// sqlc v1.28.0")}},
+			},
+		},
+		"missing template file relative to the template root": {
+			options: `{
+				"template_root": "` + jsonString(dir) + `",
+				"filename": "queries.go",
+				"template_file": "missing.tmpl"
+			}`,
+			expectedErrMsg: "failed to read the template file \"missing.tmpl\"",
+		},
+		"missing template file": {
+			options: `{
+				"filename": "queries.go",
+				"template_file": "` + jsonString(filepath.Join(dir, "missing.tmpl")) + `"
+			}`,
+			expectedErrMsg: "failed to read the template file",
+		},
+		"template and template file": {
+			options: `{
+				"filename": "queries.go",
+				"template": "",
+				"template_file": "` + jsonString(filepath.Join(dir, "query.tmpl")) + `"
+			}`,
+			expectedErrMsg: "the sqlc config 'sql[].codegen.options.template' and " +
+				"'sql[].codegen.options.template_file' fields cannot be used together",
+		},
+		"template dir without matches": {
+			options: `{
+				"template_dir": "` + jsonString(filepath.Join(dir, "*.missing")) + `",
+				"filename": "queries.go",
+				"template": ""
+			}`,
+			expectedErrMsg: "the sqlc config 'sql[].codegen.options.template_dir' field glob pattern",
+		},
+		"invalid template dir glob pattern": {
+			options: `{
+				"template_dir": "[",
+				"filename": "queries.go",
+				"template": ""
+			}`,
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.template_dir' field glob pattern \"[\"",
+		},
+		"template dir partial defined in partials": {
+			options: `{
+				"partials": {"header.tmpl": ""},
+				"template_dir": "` + jsonString(filepath.Join(dir, "partials", "*.tmpl")) + `",
+				"filename": "queries.go",
+				"template": ""
+			}`,
+			expectedErrMsg: "the partial template name \"header.tmpl\" is defined more than once",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			request := &plugin.GenerateRequest{
+				SqlcVersion:   "v1.28.0",
+				Queries:       []*plugin.Query{{Name: "GetAuthor"}},
+				PluginOptions: []byte(testCase.options),
+			}
+			response, err := code.GenerateWithConfig(request, code.Config{TemplateRoot: testCase.defaultTemplateRoot})
+
+			if testCase.expectedErrMsg != "" {
+				assert.ErrorContains(t, err, testCase.expectedErrMsg)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.EqualExportedValues(t, testCase.expected, response)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package code
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+// defaultHeaderTemplate is the header template used by the `header: true` option, it follows the Go generated code
+// convention (https://pkg.go.dev/cmd/go#hdr-Generate_Go_files_by_processing_source) and the sqlc-gen-go header.
+const defaultHeaderTemplate = `Code generated by sqlc-template. DO NOT EDIT.
+versions:
+  sqlc {{ .SqlcVersion }}
+  sqlc-template {{ .PluginVersion }}
+{{- if .Sources }}
+source: {{ join ", " .Sources }}
+{{- end }}`
+
+// headerOption is the `header` option value, either a boolean enabling the default header or a header template.
+type headerOption struct {
+	enabled  bool
+	template string
+}
+
+func (h *headerOption) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &h.enabled); err == nil {
+		h.template = defaultHeaderTemplate
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &h.template); err != nil {
+		return errors.New("the 'header' field must be a boolean or a template string")
+	}
+	h.enabled = true
+
+	return nil
+}
+
+// headerTemplateData is the root data object of the header templates.
+type headerTemplateData struct {
+	SqlcVersion   string
+	PluginVersion string
+	// Sources are the SQL query files the generated file was generated from.
+	Sources []string
+	// Filename is the name of the generated file.
+	Filename string
+}
+
+// getHeaderTemplateData returns the header root data object of a generated file.
+func getHeaderTemplateData(request *plugin.GenerateRequest, data any, filename string) *headerTemplateData {
+	sources := []string{}
+	switch data := data.(type) {
+	case *queryTemplateData:
+		sources = append(sources, data.GetFilename())
+	case *queryFileTemplateData:
+		sources = append(sources, data.Filename)
+	case *requestTemplateData:
+		for _, query := range data.GetQueries() {
+			if !slices.Contains(sources, query.GetFilename()) {
+				sources = append(sources, query.GetFilename())
+			}
+		}
+	}
+
+	return &headerTemplateData{
+		SqlcVersion:   request.GetSqlcVersion(),
+		PluginVersion: getVersion(),
+		Sources:       sources,
+		Filename:      filename,
+	}
+}
+
+// commentSyntax is how a file type writes comments, either line comments (`prefix`) or a block comment (`start` and
+// `end`).
+type commentSyntax struct {
+	prefix string
+	start  string
+	end    string
+}
+
+var (
+	slashComments     = commentSyntax{prefix: "//"}
+	hashComments      = commentSyntax{prefix: "#"}
+	dashComments      = commentSyntax{prefix: "--"}
+	semicolonComments = commentSyntax{prefix: ";"}
+	markupComments    = commentSyntax{start: "<!--", end: "-->"}
+	blockComments     = commentSyntax{start: "/*", end: "*/"}
+)
+
+// commentSyntaxes maps the file extensions to their comment syntax.
+var commentSyntaxes = map[string]commentSyntax{
+	".go":      slashComments,
+	".ts":      slashComments,
+	".tsx":     slashComments,
+	".js":      slashComments,
+	".jsx":     slashComments,
+	".mjs":     slashComments,
+	".cjs":     slashComments,
+	".rs":      slashComments,
+	".kt":      slashComments,
+	".kts":     slashComments,
+	".java":    slashComments,
+	".scala":   slashComments,
+	".cs":      slashComments,
+	".swift":   slashComments,
+	".dart":    slashComments,
+	".c":       slashComments,
+	".h":       slashComments,
+	".cc":      slashComments,
+	".cpp":     slashComments,
+	".hpp":     slashComments,
+	".zig":     slashComments,
+	".proto":   slashComments,
+	".graphql": slashComments,
+	".gql":     slashComments,
+	".py":      hashComments,
+	".pyi":     hashComments,
+	".rb":      hashComments,
+	".sh":      hashComments,
+	".bash":    hashComments,
+	".zsh":     hashComments,
+	".yaml":    hashComments,
+	".yml":     hashComments,
+	".toml":    hashComments,
+	".r":       hashComments,
+	".pl":      hashComments,
+	".ex":      hashComments,
+	".exs":     hashComments,
+	".nim":     hashComments,
+	".cr":      hashComments,
+	".tf":      hashComments,
+	".ps1":     hashComments,
+	".cfg":     hashComments,
+	".conf":    hashComments,
+	".env":     hashComments,
+	".sql":     dashComments,
+	".lua":     dashComments,
+	".hs":      dashComments,
+	".elm":     dashComments,
+	".clj":     semicolonComments,
+	".el":      semicolonComments,
+	".lisp":    semicolonComments,
+	".ini":     semicolonComments,
+	".html":    markupComments,
+	".xml":     markupComments,
+	".md":      markupComments,
+	".vue":     markupComments,
+	".svelte":  markupComments,
+	".css":     blockComments,
+	".scss":    blockComments,
+	".less":    blockComments,
+}
+
+// commentHeader returns the header text as a comment of the file type, followed by an empty line.
+func commentHeader(path string, filename string, header string) (string, error) {
+	extension := strings.ToLower(filepath.Ext(filename))
+	syntax, ok := commentSyntaxes[extension]
+	if !ok {
+		return "", fmt.Errorf(
+			"the sqlc config '%s.header' field cannot be used with the file %q, the comment syntax of the %q file "+
+				"extension is unknown",
+			path, filename, extension,
+		)
+	}
+
+	header = strings.TrimRight(header, "\n")
+	if syntax.prefix == "" {
+		return syntax.start + "\n" + header + "\n" + syntax.end + "\n\n", nil
+	}
+
+	builder := strings.Builder{}
+	for _, line := range strings.Split(header, "\n") {
+		builder.WriteString(strings.TrimRight(syntax.prefix+" "+line, " "))
+		builder.WriteByte('\n')
+	}
+	builder.WriteByte('\n')
+
+	return builder.String(), nil
+}
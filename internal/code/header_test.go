@@ -0,0 +1,240 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestHeader(t *testing.T) {
+	code.Version = "v1.2.0"
+	t.Cleanup(func() { code.Version = "" })
+
+	queries := []*plugin.Query{
+		{Name: "GetAuthor", Filename: "authors.sql"},
+		{Name: "ListBooks", Filename: "books.sql"},
+		{Name: "ListAuthors", Filename: "authors.sql"},
+	}
+
+	testCases := map[string]struct {
+		request       *plugin.GenerateRequest
+		expectedFiles []*plugin.File
+	}{
+		"go default header": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				Queries:     queries,
+				PluginOptions: []byte(`{
+					"filename": "queries.go",
+					"header": true,
+					"template": "package db\n"
+				}`),
+			},
+			expectedFiles: []*plugin.File{
+				{
+					Name: "queries.go",
+					Contents: []byte(`// Code generated by sqlc-template. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+//   sqlc-template v1.2.0
+// source: authors.sql, books.sql
+
+package db
+`),
+				},
+			},
+		},
+		"per-query python header": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				Queries:     queries[:2],
+				PluginOptions: []byte(`{
+					"filename": "{{ .Name }}.py",
+					"mode": "per-query",
+					"header": true,
+					"template": "NAME = \"{{ .Name }}\"\n"
+				}`),
+			},
+			expectedFiles: []*plugin.File{
+				{
+					Name: "GetAuthor.py",
+					Contents: []byte(`# Code generated by sqlc-template. DO NOT EDIT.
+# versions:
+#   sqlc v1.28.0
+#   sqlc-template v1.2.0
+# source: authors.sql
+
+NAME = "GetAuthor"
+`),
+				},
+				{
+					Name: "ListBooks.py",
+					Contents: []byte(`# Code generated by sqlc-template. DO NOT EDIT.
+# versions:
+#   sqlc v1.28.0
+#   sqlc-template v1.2.0
+# source: books.sql
+
+NAME = "ListBooks"
+`),
+				},
+			},
+		},
+		"per-table header without sources": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				Catalog: &plugin.Catalog{
+					Schemas: []*plugin.Schema{
+						{Name: "public", Tables: []*plugin.Table{{Rel: &plugin.Identifier{Name: "authors"}}}},
+					},
+				},
+				PluginOptions: []byte(`{
+					"filename": "{{ .Rel.Name }}.sql",
+					"mode": "per-table",
+					"header": true,
+					"template": "SELECT 1;\n"
+				}`),
+			},
+			expectedFiles: []*plugin.File{
+				{
+					Name: "authors.sql",
+					Contents: []byte(`-- Code generated by sqlc-template. DO NOT EDIT.
+-- versions:
+--   sqlc v1.28.0
+--   sqlc-template v1.2.0
+
+SELECT 1;
+`),
+				},
+			},
+		},
+		"block comment header": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "queries.html",
+					"header": "Generated from {{ .Sources | len }} files.",
+					"template": "<p></p>"
+				}`),
+			},
+			expectedFiles: []*plugin.File{
+				{Name: "queries.html", Contents: []byte("<!--\nGenerated from 0 files.\n-->\n\n<p></p>")},
+			},
+		},
+		"custom header with a go format": {
+			request: &plugin.GenerateRequest{
+				Queries: queries,
+				PluginOptions: []byte(`{
+					"partials": {"header": "unused"},
+					"filename": "queries.go",
+					"format": "go",
+					"header": "Code generated by {{ .Filename }}. DO NOT EDIT.\n\n{{ range .Sources }}{{ . }}\n{{ end }}",
+					"template": "package   db\n"
+				}`),
+			},
+			expectedFiles: []*plugin.File{
+				{
+					Name: "queries.go",
+					Contents: []byte(`// Code generated by queries.go. DO NOT EDIT.
+//
+// authors.sql
+// books.sql
+
+package db
+`),
+				},
+			},
+		},
+		"yaml header with the yaml format": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				PluginOptions: []byte(`{
+					"filename": "queries.yaml",
+					"header": "Code generated by sqlc-template. DO NOT EDIT.",
+					"template": "queries:\n    - GetAuthor\n"
+				}`),
+			},
+			expectedFiles: []*plugin.File{
+				{
+					Name:     "queries.yaml",
+					Contents: []byte("# Code generated by sqlc-template. DO NOT EDIT.\n\nqueries:\n  - GetAuthor\n"),
+				},
+			},
+		},
+		"toml header with the toml format": {
+			request: &plugin.GenerateRequest{
+				SqlcVersion: "v1.28.0",
+				PluginOptions: []byte(`{
+					"filename": "queries.toml",
+					"header": "Code generated by sqlc-template. DO NOT EDIT.",
+					"template": "[queries]\n    names = ['GetAuthor']\n"
+				}`),
+			},
+			expectedFiles: []*plugin.File{
+				{
+					Name:     "queries.toml",
+					Contents: []byte("# Code generated by sqlc-template. DO NOT EDIT.\n\n[queries]\nnames = ['GetAuthor']\n"),
+				},
+			},
+		},
+		"disabled header": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "queries.json",
+					"header": false,
+					"template": "{}"
+				}`),
+			},
+			expectedFiles: []*plugin.File{{Name: "queries.json", Contents: []byte("{}\n")}},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			response, err := code.Generate(testCase.request)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, testCase.expectedFiles, response.Files)
+		})
+	}
+}
+
+func TestHeaderFailure(t *testing.T) {
+	testCases := map[string]struct {
+		options        string
+		expectedErrMsg string
+	}{
+		"invalid header option": {
+			options:        `{"filename": "queries.go", "header": 1, "template": ""}`,
+			expectedErrMsg: "the 'header' field must be a boolean or a template string",
+		},
+		"unknown extension": {
+			options: `{"outputs": [{"filename": "queries.json", "header": true, "template": "{}"}]}`,
+			expectedErrMsg: `the sqlc config 'sql[].codegen.options.outputs[0].header' field cannot be used with the file ` +
+				`"queries.json", the comment syntax of the ".json" file extension is unknown`,
+		},
+		"header parse error": {
+			options:        `{"filename": "queries.go", "header": "{{ if }}", "template": ""}`,
+			expectedErrMsg: "failed to parse the header template, template: header:1: missing value for if",
+		},
+		"header execution error": {
+			options:        `{"filename": "queries.go", "header": "{{ .Name }}", "template": ""}`,
+			expectedErrMsg: "failed to execute the header template, template: header:1:3: executing \"header\" at <.Name>",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			_, err := code.Generate(&plugin.GenerateRequest{PluginOptions: []byte(testCase.options)})
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
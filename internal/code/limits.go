@@ -0,0 +1,385 @@
+package code
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// The default limits, a limit option set to 0 disables the limit.
+const (
+	defaultMaxFileBytes  = 64 << 20
+	defaultMaxFiles      = 10000
+	defaultTimeout       = time.Minute
+	defaultMaxListLength = 1000000
+)
+
+// The names of the template functions called by the checks that limitTemplate adds to the parsed templates.
+const (
+	checkRangeFunction    = "_checkRange"
+	checkDeadlineFunction = "_checkDeadline"
+)
+
+// limitsOptions are the `limits` option resource limits of the templates execution, they stop a runaway template (for
+// example `{{ range until 1000000000 }}`) from hanging sqlc.
+type limitsOptions struct {
+	MaxFileBytes  *int    `json:"max_file_bytes,omitempty"`
+	MaxFiles      *int    `json:"max_files,omitempty"`
+	Timeout       *string `json:"timeout,omitempty"`
+	MaxListLength *int    `json:"max_list_length,omitempty"`
+}
+
+// limits are the resource limits of a Generate call.
+type limits struct {
+	maxFileBytes  int
+	maxFiles      int
+	timeout       time.Duration
+	deadline      time.Time
+	maxListLength int
+}
+
+// getLimits validates the `limits` options and returns the limits of a Generate call starting now.
+func (o *limitsOptions) getLimits(path string) (*limits, error) {
+	l := &limits{
+		maxFileBytes:  defaultMaxFileBytes,
+		maxFiles:      defaultMaxFiles,
+		timeout:       defaultTimeout,
+		maxListLength: defaultMaxListLength,
+	}
+
+	for _, option := range []struct {
+		name   string
+		value  *int
+		target *int
+	}{
+		{"max_file_bytes", o.MaxFileBytes, &l.maxFileBytes},
+		{"max_files", o.MaxFiles, &l.maxFiles},
+		{"max_list_length", o.MaxListLength, &l.maxListLength},
+	} {
+		if option.value == nil {
+			continue
+		}
+
+		if *option.value < 0 {
+			return nil, fmt.Errorf(
+				"invalid sqlc config '%s.%s' field value %d, must not be negative", path, option.name, *option.value,
+			)
+		}
+		*option.target = *option.value
+	}
+
+	if o.Timeout != nil {
+		timeout, err := time.ParseDuration(*o.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sqlc config '%s.timeout' field value %q, %w", path, *o.Timeout, err)
+		}
+
+		if timeout < 0 {
+			return nil, fmt.Errorf("invalid sqlc config '%s.timeout' field value %q, must not be negative", path, *o.Timeout)
+		}
+		l.timeout = timeout
+	}
+
+	if l.timeout > 0 {
+		l.deadline = time.Now().Add(l.timeout)
+	}
+
+	return l, nil
+}
+
+// checkDeadline fails when the templates execution exceeded the timeout.
+func (l *limits) checkDeadline() error {
+	if !l.deadline.IsZero() && time.Now().After(l.deadline) {
+		return fmt.Errorf(
+			"the templates execution exceeded the sqlc config '%s.limits.timeout' field value of %s",
+			optionsPath, l.timeout,
+		)
+	}
+
+	return nil
+}
+
+// checkListLength fails when a list function would create a list longer than the maximum list length.
+func (l *limits) checkListLength(length int) error {
+	if l.maxListLength > 0 && length > l.maxListLength {
+		return fmt.Errorf(
+			"the list length %d exceeds the sqlc config '%s.limits.max_list_length' field value of %d",
+			length, optionsPath, l.maxListLength,
+		)
+	}
+
+	return nil
+}
+
+// checkFiles fails when more files than the maximum number of files were generated.
+func (l *limits) checkFiles(files int) error {
+	if l.maxFiles > 0 && files > l.maxFiles {
+		return fmt.Errorf(
+			"the templates generated more files than the sqlc config '%s.limits.max_files' field value of %d",
+			optionsPath, l.maxFiles,
+		)
+	}
+
+	return nil
+}
+
+// checkStringBytes fails when a function would create a string larger than the maximum file size.
+func (l *limits) checkStringBytes(size int) error {
+	if l.maxFileBytes > 0 && size > l.maxFileBytes {
+		return fmt.Errorf(
+			"the string of %d bytes exceeds the sqlc config '%s.limits.max_file_bytes' field value of %d bytes",
+			size, optionsPath, l.maxFileBytes,
+		)
+	}
+
+	return nil
+}
+
+// checkRange fails when the value of a `range` action is an integer greater than the maximum list length, the other
+// values are returned unchanged.
+func (l *limits) checkRange(value any) (any, error) {
+	exceeds := false
+	switch value := reflect.ValueOf(value); value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		exceeds = value.Int() > int64(l.maxListLength)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		exceeds = value.Uint() > uint64(l.maxListLength)
+	}
+
+	if l.maxListLength > 0 && exceeds {
+		return nil, fmt.Errorf(
+			"the range over %v exceeds the sqlc config '%s.limits.max_list_length' field value of %d",
+			value, optionsPath, l.maxListLength,
+		)
+	}
+
+	return value, nil
+}
+
+// seqLength returns the length of the list of numbers printed by the sprig `seq` function.
+func seqLength(params []int) int {
+	start, step, end := 1, 1, 0
+	switch len(params) {
+	case 1:
+		end = params[0]
+	case 2:
+		start, end = params[0], params[1]
+	case 3:
+		start, step, end = params[0], params[1], params[2]
+	default:
+		return 0
+	}
+
+	if len(params) < 3 && end < start {
+		step = -1
+	}
+
+	if step == 0 || (end-start)/step < 0 {
+		return 0
+	}
+
+	return (end-start)/step + 1
+}
+
+// limitFunctions limits the length of the lists created by the `until`, `untilStep` and `seq` functions, the size of
+// the strings created by the functions whose result can be much larger than their arguments (like `repeat`) and makes
+// every template function fail once the timeout is exceeded, so that a template looping over function calls is stopped.
+// It also defines the functions called by the checks of limitTemplate.
+func (l *limits) limitFunctions(funcMap template.FuncMap) {
+	until := funcMap["until"].(func(int) []int)
+	funcMap["until"] = func(count int) ([]int, error) {
+		if err := l.checkListLength(count); err != nil {
+			return nil, err
+		}
+
+		return until(count), nil
+	}
+
+	untilStep := funcMap["untilStep"].(func(int, int, int) []int)
+	funcMap["untilStep"] = func(start int, stop int, step int) ([]int, error) {
+		if step != 0 {
+			if err := l.checkListLength((stop - start) / step); err != nil {
+				return nil, err
+			}
+		}
+
+		return untilStep(start, stop, step), nil
+	}
+
+	seq := funcMap["seq"].(func(...int) string)
+	funcMap["seq"] = func(params ...int) (string, error) {
+		if err := l.checkListLength(seqLength(params)); err != nil {
+			return "", err
+		}
+
+		return seq(params...), nil
+	}
+
+	repeat := funcMap["repeat"].(func(int, string) string)
+	funcMap["repeat"] = func(count int, str string) (string, error) {
+		if err := l.checkStringBytes(count * len(str)); err != nil {
+			return "", err
+		}
+
+		return repeat(count, str), nil
+	}
+
+	for _, name := range []string{"indent", "nindent"} {
+		indent := funcMap[name].(func(int, string) string)
+		funcMap[name] = func(spaces int, str string) (string, error) {
+			if err := l.checkStringBytes(len(str) + spaces*(strings.Count(str, "\n")+1)); err != nil {
+				return "", err
+			}
+
+			return indent(spaces, str), nil
+		}
+	}
+
+	// The random functions are replaced by functions of a different type in the deterministic mode.
+	for _, name := range []string{"randAlphaNum", "randAlpha", "randAscii", "randNumeric"} {
+		if random, ok := funcMap[name].(func(int) string); ok {
+			funcMap[name] = func(count int) (string, error) {
+				if err := l.checkStringBytes(count); err != nil {
+					return "", err
+				}
+
+				return random(count), nil
+			}
+		}
+	}
+
+	if randBytes, ok := funcMap["randBytes"].(func(int) (string, error)); ok {
+		funcMap["randBytes"] = func(count int) (string, error) {
+			// The random bytes are encoded as base64, 4 characters per 3 bytes.
+			if err := l.checkStringBytes(count / 3 * 4); err != nil {
+				return "", err
+			}
+
+			return randBytes(count)
+		}
+	}
+
+	if !l.deadline.IsZero() {
+		for name, function := range funcMap {
+			value := reflect.ValueOf(function)
+			funcMap[name] = reflect.MakeFunc(value.Type(), func(args []reflect.Value) []reflect.Value {
+				// text/template turns the panics of the functions into execution errors.
+				if err := l.checkDeadline(); err != nil {
+					panic(err)
+				}
+
+				if value.Type().IsVariadic() {
+					return value.CallSlice(args)
+				}
+
+				return value.Call(args)
+			}).Interface()
+		}
+	}
+
+	funcMap[checkRangeFunction] = l.checkRange
+	funcMap[checkDeadlineFunction] = func() (string, error) {
+		return "", l.checkDeadline()
+	}
+}
+
+// limitTemplate adds checks to the `range` actions of every template of a template set, so that the limits are also
+// enforced on the loops that neither call a function nor write to the file: ranging over an integer greater than the
+// maximum list length fails and every iteration fails once the timeout is exceeded.
+func (l *limits) limitTemplate(tmpl *template.Template) {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			limitNode(t.Tree, t.Root)
+		}
+	}
+}
+
+// limitNode adds the limits checks to the `range` actions of a node and of its children, the `range` actions already
+// checked, of the templates shared by several template sets, are left unchanged.
+func limitNode(tree *parse.Tree, node parse.Node) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+
+		for _, child := range node.Nodes {
+			limitNode(tree, child)
+		}
+	case *parse.IfNode:
+		limitNode(tree, node.List)
+		limitNode(tree, node.ElseList)
+	case *parse.WithNode:
+		limitNode(tree, node.List)
+		limitNode(tree, node.ElseList)
+	case *parse.RangeNode:
+		if isCheckCommand(node.Pipe.Cmds[len(node.Pipe.Cmds)-1], checkRangeFunction) {
+			return
+		}
+
+		// `{{ range pipeline }}` becomes `{{ range pipeline | _checkRange }}{{ _checkDeadline }}`.
+		node.Pipe.Cmds = append(node.Pipe.Cmds, newCheckCommand(tree, node.Pos, checkRangeFunction))
+		node.List.Nodes = append([]parse.Node{&parse.ActionNode{
+			NodeType: parse.NodeAction,
+			Pos:      node.List.Pos,
+			Pipe: &parse.PipeNode{
+				NodeType: parse.NodePipe,
+				Pos:      node.List.Pos,
+				Cmds:     []*parse.CommandNode{newCheckCommand(tree, node.List.Pos, checkDeadlineFunction)},
+			},
+		}}, node.List.Nodes...)
+
+		limitNode(tree, node.List)
+		limitNode(tree, node.ElseList)
+	}
+}
+
+// newCheckCommand returns a command node calling a check function, the value of the previous command of the pipeline
+// is passed as argument.
+func newCheckCommand(tree *parse.Tree, pos parse.Pos, function string) *parse.CommandNode {
+	return &parse.CommandNode{
+		NodeType: parse.NodeCommand,
+		Pos:      pos,
+		Args:     []parse.Node{parse.NewIdentifier(function).SetTree(tree).SetPos(pos)},
+	}
+}
+
+func isCheckCommand(command *parse.CommandNode, function string) bool {
+	if len(command.Args) != 1 {
+		return false
+	}
+
+	identifier, ok := command.Args[0].(*parse.IdentifierNode)
+
+	return ok && identifier.Ident == function
+}
+
+// limitedBuffer is the buffer a template writes a generated file to, it fails when the file exceeds the maximum file
+// size or when the timeout is exceeded.
+type limitedBuffer struct {
+	buf    bytes.Buffer
+	limits *limits
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limits.maxFileBytes > 0 && b.buf.Len()+len(p) > b.limits.maxFileBytes {
+		return 0, fmt.Errorf(
+			"the generated file exceeds the sqlc config '%s.limits.max_file_bytes' field value of %d bytes",
+			optionsPath, b.limits.maxFileBytes,
+		)
+	}
+
+	if err := b.limits.checkDeadline(); err != nil {
+		return 0, err
+	}
+
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
@@ -0,0 +1,237 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestLimits(t *testing.T) {
+	queries := []*plugin.Query{{Name: "GetAuthor"}, {Name: "ListAuthors"}, {Name: "DeleteAuthor"}}
+
+	testCases := map[string]struct {
+		request        *plugin.GenerateRequest
+		expectedFiles  int
+		expectedErrMsg string
+	}{
+		"max file bytes": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"max_file_bytes": 10},
+					"filename": "",
+					"template": "{{ range until 11 }}x{{ end }}"
+				}`),
+			},
+			expectedErrMsg: "failed to execute the template, the generated file exceeds the sqlc config " +
+				"'sql[].codegen.options.limits.max_file_bytes' field value of 10 bytes",
+		},
+		"max file bytes not exceeded": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"max_file_bytes": 10},
+					"filename": "",
+					"template": "{{ range until 10 }}x{{ end }}"
+				}`),
+			},
+			expectedFiles: 1,
+		},
+		"default max file bytes": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "queries.txt",
+					"template": "{{ range until 1000 }}{{ repeat 100000 \"x\" }}{{ end }}"
+				}`),
+			},
+			expectedErrMsg: "field value of 67108864 bytes",
+		},
+		"max files": {
+			request: &plugin.GenerateRequest{
+				Queries: queries,
+				PluginOptions: []byte(`{
+					"limits": {"max_files": 2},
+					"mode": "per-query",
+					"filename": "{{ .Name }}.txt",
+					"template": ""
+				}`),
+			},
+			expectedErrMsg: "the templates generated more files than the sqlc config " +
+				"'sql[].codegen.options.limits.max_files' field value of 2",
+		},
+		"max files across outputs": {
+			request: &plugin.GenerateRequest{
+				Queries: queries,
+				PluginOptions: []byte(`{
+					"limits": {"max_files": 3},
+					"outputs": [
+						{"filename": "queries.txt", "template": ""},
+						{"filename": "{{ .Name }}.txt", "mode": "per-query", "template": ""}
+					]
+				}`),
+			},
+			expectedErrMsg: "failed to generate the sqlc config 'sql[].codegen.options.outputs[1]' output, the templates " +
+				"generated more files than the sqlc config 'sql[].codegen.options.limits.max_files' field value of 3",
+		},
+		"disabled max files": {
+			request: &plugin.GenerateRequest{
+				Queries: queries,
+				PluginOptions: []byte(`{
+					"limits": {"max_files": 0},
+					"mode": "per-query",
+					"filename": "{{ .Name }}.txt",
+					"template": ""
+				}`),
+			},
+			expectedFiles: 3,
+		},
+		"max list length": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "queries.txt",
+					"template": "{{ range until 1000000000 }}x{{ end }}"
+				}`),
+			},
+			expectedErrMsg: "error calling until: the list length 1000000000 exceeds the sqlc config " +
+				"'sql[].codegen.options.limits.max_list_length' field value of 1000000",
+		},
+		"max list length untilStep": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"max_list_length": 5},
+					"filename": "queries.txt",
+					"template": "{{ range untilStep 0 12 2 }}x{{ end }}"
+				}`),
+			},
+			expectedErrMsg: "error calling untilStep: the list length 6 exceeds the sqlc config " +
+				"'sql[].codegen.options.limits.max_list_length' field value of 5",
+		},
+		"max list length range over an integer": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "queries.txt",
+					"template": "{{ range 1000000000 }}{{ end }}"
+				}`),
+			},
+			expectedErrMsg: "error calling _checkRange: the range over 1000000000 exceeds the sqlc config " +
+				"'sql[].codegen.options.limits.max_list_length' field value of 1000000",
+		},
+		"max list length range over a function result": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"max_list_length": 5},
+					"partials": {"loop": "{{ range $i, $v := add . 1 }}{{ end }}"},
+					"filename": "queries.txt",
+					"template": "{{ range 5 }}{{ end }}{{ template \"loop\" 5 }}"
+				}`),
+			},
+			expectedErrMsg: "error calling _checkRange: the range over 6 exceeds the sqlc config " +
+				"'sql[].codegen.options.limits.max_list_length' field value of 5",
+		},
+		"max list length range over a list": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"max_list_length": 2},
+					"filename": "queries.txt",
+					"template": "{{ range list 1 2 3 }}{{ . }}{{ else }}empty{{ end }}"
+				}`),
+			},
+			expectedFiles: 1,
+		},
+		"max list length seq": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "queries.txt",
+					"template": "{{ seq 0 2 3000000 }}"
+				}`),
+			},
+			expectedErrMsg: "error calling seq: the list length 1500001 exceeds the sqlc config " +
+				"'sql[].codegen.options.limits.max_list_length' field value of 1000000",
+		},
+		"max file bytes repeat": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "queries.txt",
+					"template": "{{ $x := repeat 2000000000 \"xx\" }}"
+				}`),
+			},
+			expectedErrMsg: "error calling repeat: the string of 4000000000 bytes exceeds the sqlc config " +
+				"'sql[].codegen.options.limits.max_file_bytes' field value of 67108864 bytes",
+		},
+		"max file bytes indent": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"max_file_bytes": 100},
+					"filename": "queries.txt",
+					"template": "{{ $x := nindent 50 \"a\\nb\" }}"
+				}`),
+			},
+			expectedErrMsg: "error calling nindent: the string of 103 bytes exceeds the sqlc config " +
+				"'sql[].codegen.options.limits.max_file_bytes' field value of 100 bytes",
+		},
+		"timeout": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"timeout": "100ms", "max_list_length": 0},
+					"filename": "",
+					"template": "{{ range 1000000000 }}{{ end }}"
+				}`),
+			},
+			expectedErrMsg: "the templates execution exceeded the sqlc config 'sql[].codegen.options.limits.timeout' " +
+				"field value of 100ms",
+		},
+		"timeout in nested ranges": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"timeout": "100ms"},
+					"filename": "",
+					"template": "{{ range 1000 }}{{ range 1000 }}{{ range 1000 }}{{ end }}{{ end }}{{ end }}"
+				}`),
+			},
+			expectedErrMsg: "the templates execution exceeded the sqlc config 'sql[].codegen.options.limits.timeout' " +
+				"field value of 100ms",
+		},
+		"timeout in a function": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"limits": {"timeout": "1ns"},
+					"filename": "",
+					"template": "{{ $x := upper \"x\" }}"
+				}`),
+			},
+			expectedErrMsg: "error calling upper: the templates execution exceeded the sqlc config " +
+				"'sql[].codegen.options.limits.timeout' field value of 1ns",
+		},
+		"invalid timeout": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{"limits": {"timeout": "1 minute"}, "filename": "", "template": ""}`),
+			},
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.limits.timeout' field value \"1 minute\"",
+		},
+		"negative limit": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{"limits": {"max_files": -1}, "filename": "", "template": ""}`),
+			},
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.limits.max_files' field value -1, must not be " +
+				"negative",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			response, err := code.Generate(testCase.request)
+			if testCase.expectedErrMsg != "" {
+				assert.ErrorContains(t, err, testCase.expectedErrMsg)
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, response.Files, testCase.expectedFiles)
+		})
+	}
+}
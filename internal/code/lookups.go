@@ -0,0 +1,127 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+// requestTemplateData is the root data object of the templates rendered in the "single" mode and the `{{ .Request }}`
+// field of the other modes. It keeps every GenerateRequest field and adds the catalog lookup methods.
+type requestTemplateData struct {
+	*plugin.GenerateRequest
+	// strict is the `strict` option, the unknown query commands fail in strict mode.
+	strict bool
+}
+
+// parseIdentifier returns the identifier of a "name" or "schema.name" string.
+func parseIdentifier(name string) *plugin.Identifier {
+	if schema, name, ok := strings.Cut(name, "."); ok {
+		return &plugin.Identifier{Schema: schema, Name: name}
+	}
+
+	return &plugin.Identifier{Name: name}
+}
+
+// findSchema returns the catalog schema of an identifier, identifiers without a schema belong to the catalog default
+// schema.
+func findSchema(request *plugin.GenerateRequest, identifier *plugin.Identifier) *plugin.Schema {
+	schemaName := identifier.GetSchema()
+	if schemaName == "" {
+		schemaName = request.GetCatalog().GetDefaultSchema()
+	}
+
+	for _, schema := range request.GetCatalog().GetSchemas() {
+		if schema.GetName() == schemaName {
+			return schema
+		}
+	}
+
+	return nil
+}
+
+// findTable returns the catalog table of a table identifier or nil if the table is not part of the catalog.
+func findTable(request *plugin.GenerateRequest, identifier *plugin.Identifier) *plugin.Table {
+	for _, table := range findSchema(request, identifier).GetTables() {
+		if table.GetRel().GetName() == identifier.GetName() {
+			return table
+		}
+	}
+
+	return nil
+}
+
+// LookupTable returns the catalog table of a table identifier (like the `.Table` of a column), of the table of a
+// column or of a "table" or "schema.table" name. It returns nil if the table is not part of the catalog.
+func (r *requestTemplateData) LookupTable(table any) (*plugin.Table, error) {
+	switch table := table.(type) {
+	case *plugin.Identifier:
+		return findTable(r.GenerateRequest, table), nil
+	case *plugin.Column:
+		if table.GetTable() == nil {
+			return nil, nil
+		}
+
+		return findTable(r.GenerateRequest, table.GetTable()), nil
+	case string:
+		return findTable(r.GenerateRequest, parseIdentifier(table)), nil
+	default:
+		return nil, fmt.Errorf("LookupTable: expected an identifier, a column or a string, got %T", table)
+	}
+}
+
+// LookupEnum returns the catalog enum of a type identifier (like the `.Type` of a column), of the type of a column or
+// of an "enum" or "schema.enum" name. It returns nil if the enum is not part of the catalog.
+func (r *requestTemplateData) LookupEnum(enum any) (*plugin.Enum, error) {
+	switch enum := enum.(type) {
+	case *plugin.Identifier:
+		_, found := findEnum(r.GenerateRequest, &plugin.Column{Type: enum})
+		return found, nil
+	case *plugin.Column:
+		_, found := findEnum(r.GenerateRequest, enum)
+		return found, nil
+	case string:
+		_, found := findEnum(r.GenerateRequest, &plugin.Column{Type: parseIdentifier(enum)})
+		return found, nil
+	default:
+		return nil, fmt.Errorf("LookupEnum: expected an identifier, a column or a string, got %T", enum)
+	}
+}
+
+// IsEnum reports whether the type of a column is a catalog enum.
+func (r *requestTemplateData) IsEnum(column *plugin.Column) bool {
+	_, enum := findEnum(r.GenerateRequest, column)
+
+	return enum != nil
+}
+
+// TableForQuery returns the catalog table a query works on: the table of an INSERT query or the table every result
+// column belongs to. It returns nil if the query does not work on a single catalog table.
+func (r *requestTemplateData) TableForQuery(query *plugin.Query) *plugin.Table {
+	if query.GetInsertIntoTable() != nil {
+		return findTable(r.GenerateRequest, query.GetInsertIntoTable())
+	}
+
+	var identifier *plugin.Identifier
+	for _, column := range query.GetColumns() {
+		if column.GetTable() == nil {
+			return nil
+		}
+
+		if identifier == nil {
+			identifier = column.GetTable()
+			continue
+		}
+
+		if findTable(r.GenerateRequest, identifier) != findTable(r.GenerateRequest, column.GetTable()) {
+			return nil
+		}
+	}
+
+	if identifier == nil {
+		return nil
+	}
+
+	return findTable(r.GenerateRequest, identifier)
+}
@@ -0,0 +1,150 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func createLookupTestGenerateRequest(options string) *plugin.GenerateRequest {
+	authors := &plugin.Identifier{Name: "authors"}
+	books := &plugin.Identifier{Schema: "public", Name: "books"}
+
+	return &plugin.GenerateRequest{
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{
+				{
+					Name: "public",
+					Tables: []*plugin.Table{
+						{Rel: authors, Comment: "public authors"},
+						{Rel: books, Comment: "public books"},
+					},
+					Enums: []*plugin.Enum{{Name: "status", Vals: []string{"draft", "published"}}},
+				},
+				{
+					Name: "archive",
+					Tables: []*plugin.Table{
+						{Rel: &plugin.Identifier{Schema: "archive", Name: "authors"}, Comment: "archived authors"},
+					},
+					Enums: []*plugin.Enum{{Name: "status", Vals: []string{"archived"}}},
+				},
+			},
+		},
+		Queries: []*plugin.Query{
+			{
+				Name: "GetAuthor",
+				Columns: []*plugin.Column{
+					{Name: "id", Table: authors, Type: &plugin.Identifier{Name: "int8"}},
+					{Name: "status", Table: authors, Type: &plugin.Identifier{Name: "status"}},
+				},
+			},
+			{
+				Name: "ListAuthorBooks",
+				Columns: []*plugin.Column{
+					{Name: "name", Table: authors, Type: &plugin.Identifier{Name: "text"}},
+					{Name: "title", Table: books, Type: &plugin.Identifier{Name: "text"}},
+				},
+			},
+			{
+				Name:            "CreateBook",
+				InsertIntoTable: &plugin.Identifier{Name: "books"},
+				Columns:         []*plugin.Column{{Name: "id", Type: &plugin.Identifier{Name: "int8"}}},
+			},
+			{
+				Name:    "CountAuthors",
+				Columns: []*plugin.Column{{Name: "count", Type: &plugin.Identifier{Name: "int8"}}},
+			},
+		},
+		PluginOptions: []byte(options),
+	}
+}
+
+func TestLookups(t *testing.T) {
+	testCases := map[string]struct {
+		template         string
+		mode             string
+		expectedContents []string
+	}{
+		"LookupTable": {
+			template: `{{ with $.LookupTable "authors" }}{{ .Comment }}{{ end }}, ` +
+				`{{ with $.LookupTable "archive.authors" }}{{ .Comment }}{{ end }}, ` +
+				`{{ with $.LookupTable "public.books" }}{{ .Comment }}{{ end }}, ` +
+				`{{ $.LookupTable "missing" }}`,
+			expectedContents: []string{"public authors, archived authors, public books, <nil>"},
+		},
+		"LookupTable of columns": {
+			template: `{{ range .Queries }}{{ range .Columns }}{{ with $.LookupTable .Table }}{{ .Comment }}{{ else }}none` +
+				`{{ end }};{{ end }}{{ end }}`,
+			expectedContents: []string{"public authors;public authors;public authors;public books;none;none;"},
+		},
+		"LookupTable of a column": {
+			template:         `{{ with $.LookupTable (index (index .Queries 1).Columns 1) }}{{ .Comment }}{{ end }}`,
+			expectedContents: []string{"public books"},
+		},
+		"LookupEnum": {
+			template: `{{ ($.LookupEnum "status").Vals }} {{ ($.LookupEnum "archive.status").Vals }} ` +
+				`{{ $.LookupEnum "missing" }}`,
+			expectedContents: []string{"[draft published] [archived] <nil>"},
+		},
+		"LookupEnum of a column": {
+			template: `{{ range (index .Queries 0).Columns }}{{ with $.LookupEnum . }}{{ .Name }}{{ else }}none{{ end }};` +
+				`{{ end }}{{ with $.LookupEnum (index (index .Queries 0).Columns 1).Type }}{{ .Name }}{{ end }}`,
+			expectedContents: []string{"none;status;status"},
+		},
+		"IsEnum": {
+			template:         `{{ range (index .Queries 0).Columns }}{{ .Name }}={{ $.IsEnum . }};{{ end }}`,
+			expectedContents: []string{"id=false;status=true;"},
+		},
+		"TableForQuery": {
+			template: `{{ range .Queries }}{{ .Name }}={{ with $.TableForQuery . }}{{ .Rel.Name }}{{ else }}none{{ end }};` +
+				`{{ end }}`,
+			expectedContents: []string{"GetAuthor=authors;ListAuthorBooks=none;CreateBook=books;CountAuthors=none;"},
+		},
+		"per-query mode Request": {
+			mode: "per-query",
+			template: `{{ .Name }}={{ with .Request.TableForQuery .Query }}{{ .Rel.Name }}{{ else }}none{{ end }} ` +
+				`{{ len .Request.Queries }}`,
+			expectedContents: []string{
+				"GetAuthor=authors 4", "ListAuthorBooks=none 4", "CreateBook=books 4", "CountAuthors=none 4",
+			},
+		},
+		"per-table mode Request": {
+			mode:             "per-table",
+			template:         `{{ with .Request.LookupTable .Rel }}{{ .Comment }}{{ end }}`,
+			expectedContents: []string{"public authors", "public books", "archived authors"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			mode, filename := "single", "queries.txt"
+			switch testCase.mode {
+			case "per-query":
+				mode, filename = testCase.mode, "{{ .Name }}.txt"
+			case "per-table":
+				mode, filename = testCase.mode, "{{ .Rel.Schema }}.{{ .Rel.Name }}.txt"
+			}
+
+			response, err := code.Generate(createLookupTestGenerateRequest(`{
+				"mode": "` + mode + `",
+				"filename": "` + filename + `",
+				"template": "` + jsonString(testCase.template) + `"
+			}`))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			contents := []string{}
+			for _, file := range response.Files {
+				contents = append(contents, string(file.Contents))
+			}
+			assert.Equal(t, testCase.expectedContents, contents)
+		})
+	}
+}
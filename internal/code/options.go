@@ -0,0 +1,231 @@
+package code
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// optionsPath is the sqlc config path of the plugin options, used in error messages.
+const optionsPath = "sql[].codegen.options"
+
+// TemplateRootEnv is the environment variable the plugin entry point reads the Config TemplateRoot from.
+const TemplateRootEnv = "SQLC_TEMPLATE_ROOT"
+
+// Config holds the plugin settings that do not come from the sqlc config plugin options.
+type Config struct {
+	// TemplateRoot is the directory the relative template file paths are resolved from when the `template_root`
+	// option is not defined. When empty the relative paths are resolved from the plugin working directory.
+	TemplateRoot string
+}
+
+// outputOptions defines how one or more files are generated from a single template.
+type outputOptions struct {
+	Filename     *string       `json:"filename,omitempty"`
+	Template     *string       `json:"template,omitempty"`
+	TemplateFile *string       `json:"template_file,omitempty"`
+	Mode         *string       `json:"mode,omitempty"`
+	GroupBy      *string       `json:"group_by,omitempty"`
+	Format       *string       `json:"format,omitempty"`
+	Header       *headerOption `json:"header,omitempty"`
+
+	// path is the sqlc config path of these options, used in error messages.
+	path string
+}
+
+type pluginOptions struct {
+	outputOptions
+	Outputs     []outputOptions   `json:"outputs,omitempty"`
+	Partials    map[string]string `json:"partials,omitempty"`
+	TemplateDir *string           `json:"template_dir,omitempty"`
+	// TemplateRoot is the directory the relative `template_file` and `template_dir` paths are resolved from.
+	TemplateRoot *string        `json:"template_root,omitempty"`
+	DebugDump    *string        `json:"debug_dump,omitempty"`
+	Overrides    []typeOverride `json:"overrides,omitempty"`
+	// Strict makes the templates fail when accessing missing map keys or printing missing or nil values.
+	Strict bool `json:"strict,omitempty"`
+	// Deterministic disables the template functions whose result changes between runs.
+	Deterministic bool          `json:"deterministic,omitempty"`
+	Limits        limitsOptions `json:"limits"`
+}
+
+func parsePluginOptions(options []byte) (*pluginOptions, error) {
+	pluginOptions := &pluginOptions{}
+	if err := json.Unmarshal(options, pluginOptions); err != nil {
+		return nil, fmt.Errorf("failed to parse the sqlc config '%s' field to JSON, %w", optionsPath, err)
+	}
+
+	pluginOptions.path = optionsPath
+	for i := range pluginOptions.Outputs {
+		pluginOptions.Outputs[i].path = fmt.Sprintf("%s.outputs[%d]", optionsPath, i)
+	}
+
+	for i := range pluginOptions.Overrides {
+		if err := pluginOptions.Overrides[i].validate(fmt.Sprintf("%s.overrides[%d]", optionsPath, i)); err != nil {
+			return nil, err
+		}
+	}
+
+	return pluginOptions, nil
+}
+
+// getOutputs returns the options of every output to generate.
+// The root `filename` and `template` (or `template_file`) options define an output unless only the `outputs` or
+// `debug_dump` options are used.
+func (o *pluginOptions) getOutputs() ([]*outputOptions, error) {
+	outputs := []*outputOptions{}
+	if (len(o.Outputs) == 0 && o.DebugDump == nil) || o.Filename != nil || o.Template != nil || o.TemplateFile != nil {
+		outputs = append(outputs, &o.outputOptions)
+	}
+	for i := range o.Outputs {
+		outputs = append(outputs, &o.Outputs[i])
+	}
+
+	for _, output := range outputs {
+		if err := output.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return outputs, nil
+}
+
+func (o *outputOptions) validate() error {
+	if o.Filename == nil {
+		return fmt.Errorf("missing the sqlc config '%s.filename' field", o.path)
+	}
+
+	if o.Template != nil && o.TemplateFile != nil {
+		return fmt.Errorf(
+			"the sqlc config '%s.template' and '%s.template_file' fields cannot be used together", o.path, o.path,
+		)
+	}
+
+	if o.Template == nil && o.TemplateFile == nil {
+		return fmt.Errorf("missing the sqlc '%s.template' field", o.path)
+	}
+
+	if o.Format != nil && !slices.Contains(formats, *o.Format) {
+		return fmt.Errorf(
+			"invalid sqlc config '%s.format' field value %q, must be one of: %q", o.path, *o.Format, formats,
+		)
+	}
+
+	if !slices.Contains(modes, o.getMode()) {
+		return fmt.Errorf(
+			"invalid sqlc config '%s.mode' field value %q, must be one of: %q", o.path, o.getMode(), modes,
+		)
+	}
+
+	if o.GroupBy != nil {
+		if *o.GroupBy != groupByQueryFile {
+			return fmt.Errorf(
+				"invalid sqlc config '%s.group_by' field value %q, must be %q", o.path, *o.GroupBy, groupByQueryFile,
+			)
+		}
+
+		if o.getMode() != modeSingle {
+			return fmt.Errorf(
+				"the sqlc config '%s.group_by' field can only be used with the %q mode", o.path, modeSingle,
+			)
+		}
+	}
+
+	return nil
+}
+
+// getTemplateRoot returns the directory the relative template file paths are resolved from, the `template_root` option
+// or else the default root. An empty string resolves them from the plugin working directory which, when running as an
+// sqlc process plugin, is the directory where sqlc was executed from.
+func (o *pluginOptions) getTemplateRoot(defaultRoot string) string {
+	if o.TemplateRoot != nil {
+		return *o.TemplateRoot
+	}
+
+	return defaultRoot
+}
+
+// resolveTemplatePath returns the path joined to the root directory, absolute paths are returned unchanged.
+func resolveTemplatePath(root string, path string) string {
+	if root == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(root, path)
+}
+
+// readTemplateFile reads a template file, relative paths are resolved from the root directory.
+func readTemplateFile(root string, path string) (string, error) {
+	contents, err := os.ReadFile(resolveTemplatePath(root, path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the template file %q, %w", path, err)
+	}
+
+	return string(contents), nil
+}
+
+// getTemplate returns the template text of the `template` option or the contents of the `template_file` option file.
+func (o *outputOptions) getTemplate(root string) (string, error) {
+	if o.TemplateFile != nil {
+		return readTemplateFile(root, *o.TemplateFile)
+	}
+
+	return *o.Template, nil
+}
+
+// getPartials returns the `partials` option templates together with the templates of every file matched by the
+// `template_dir` option glob pattern, named after the file base name. Relative patterns are resolved from the root.
+func (o *pluginOptions) getPartials(root string) (map[string]string, error) {
+	partials := make(map[string]string, len(o.Partials))
+	for name, text := range o.Partials {
+		partials[name] = text
+	}
+
+	if o.TemplateDir == nil {
+		return partials, nil
+	}
+
+	paths, err := filepath.Glob(resolveTemplatePath(root, *o.TemplateDir))
+	if err != nil {
+		return nil, fmt.Errorf("invalid sqlc config '%s.template_dir' field glob pattern %q, %w", o.path, *o.TemplateDir, err)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf(
+			"the sqlc config '%s.template_dir' field glob pattern %q did not match any file", o.path, *o.TemplateDir,
+		)
+	}
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if _, ok := partials[name]; ok {
+			return nil, fmt.Errorf("the partial template name %q is defined more than once", name)
+		}
+
+		text, err := readTemplateFile("", path)
+		if err != nil {
+			return nil, err
+		}
+		partials[name] = text
+	}
+
+	return partials, nil
+}
+
+func (o *outputOptions) getMode() string {
+	if o.Mode == nil {
+		return modeSingle
+	}
+
+	return *o.Mode
+}
+
+func (o *outputOptions) getGroupBy() string {
+	if o.GroupBy == nil {
+		return ""
+	}
+
+	return *o.GroupBy
+}
@@ -0,0 +1,118 @@
+package code
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+// typeOverride replaces the type returned by the type mapping template functions for the matching columns.
+type typeOverride struct {
+	// DBType matches the columns of a database type, for example "uuid".
+	DBType *string `json:"db_type,omitempty"`
+	// Column matches a single table column, formatted as "table.column" or "schema.table.column".
+	Column *string `json:"column,omitempty"`
+	// Nullable, if defined, only matches nullable (true) or not null (false) columns.
+	Nullable *bool `json:"nullable,omitempty"`
+	// Engine, if defined, only matches the columns of this engine.
+	Engine *string `json:"engine,omitempty"`
+
+	GoType     *string `json:"go_type,omitempty"`
+	TsType     *string `json:"ts_type,omitempty"`
+	RustType   *string `json:"rust_type,omitempty"`
+	KotlinType *string `json:"kotlin_type,omitempty"`
+	PythonType *string `json:"python_type,omitempty"`
+	CSharpType *string `json:"csharp_type,omitempty"`
+}
+
+func (o *typeOverride) validate(path string) error {
+	if (o.DBType == nil) == (o.Column == nil) {
+		return fmt.Errorf("the sqlc config '%s' field must define either the 'db_type' or the 'column' field", path)
+	}
+
+	if o.Column != nil {
+		if parts := strings.Split(*o.Column, "."); len(parts) < 2 || len(parts) > 3 {
+			return fmt.Errorf(
+				"invalid sqlc config '%s.column' field value %q, must be formatted as \"table.column\" or "+
+					"\"schema.table.column\"",
+				path, *o.Column,
+			)
+		}
+	}
+
+	if o.Engine != nil {
+		if _, ok := sqlTypes[*o.Engine]; !ok {
+			return fmt.Errorf("invalid sqlc config '%s.engine' field value %q, must be one of: %q", path, *o.Engine, engines)
+		}
+	}
+
+	return nil
+}
+
+// matchesColumn returns true if the override column is the column, overrides and columns without a schema belong to
+// the catalog default schema. Aliased query columns are matched by their original table column name.
+func (o *typeOverride) matchesColumn(request *plugin.GenerateRequest, column *plugin.Column) bool {
+	if column.GetTable() == nil {
+		return false
+	}
+
+	parts := strings.Split(*o.Column, ".")
+	if len(parts) == 2 {
+		parts = append([]string{request.GetCatalog().GetDefaultSchema()}, parts...)
+	}
+
+	schema := column.GetTable().GetSchema()
+	if schema == "" {
+		schema = request.GetCatalog().GetDefaultSchema()
+	}
+
+	return parts[0] == schema && parts[1] == column.GetTable().GetName() &&
+		parts[2] == cmp.Or(column.GetOriginalName(), column.GetName())
+}
+
+// matches returns true if the override matches the column element type, array elements and sqlc.slice() values are
+// never nullable.
+func (o *typeOverride) matches(request *plugin.GenerateRequest, engine string, column *plugin.Column) bool {
+	if o.Engine != nil && *o.Engine != engine {
+		return false
+	}
+
+	notNull := column.GetNotNull() || getArrayDims(column) > 0
+	if o.Nullable != nil && *o.Nullable == notNull {
+		return false
+	}
+
+	if o.Column != nil {
+		return o.matchesColumn(request, column)
+	}
+
+	return getColumnTypeName(&plugin.Column{Type: &plugin.Identifier{Name: *o.DBType}}) == getColumnTypeName(column)
+}
+
+// findTypeOverride returns the type of the first override that matches the column and defines a type for the template
+// function, the column overrides take precedence over the database type overrides.
+// For array columns and sqlc.slice() parameters the returned type is the element type, the caller adds the array type.
+func findTypeOverride(
+	request *plugin.GenerateRequest,
+	overrides []typeOverride,
+	engine string,
+	column *plugin.Column,
+	getType func(*typeOverride) *string,
+) (string, bool) {
+	for _, byColumn := range []bool{true, false} {
+		for i := range overrides {
+			override := &overrides[i]
+			if (override.Column != nil) != byColumn || getType(override) == nil {
+				continue
+			}
+
+			if override.matches(request, engine, column) {
+				return *getType(override), true
+			}
+		}
+	}
+
+	return "", false
+}
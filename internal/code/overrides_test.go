@@ -0,0 +1,193 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func createOverridesTestGenerateRequest(engine string, overrides string) *plugin.GenerateRequest {
+	authors := &plugin.Identifier{Name: "authors"}
+	audit := &plugin.Identifier{Schema: "audit", Name: "authors"}
+
+	return &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: engine},
+		Catalog:  &plugin.Catalog{DefaultSchema: "public"},
+		Queries: []*plugin.Query{
+			{
+				Name: "query",
+				Columns: []*plugin.Column{
+					{Name: "id", NotNull: true, Table: authors, Type: &plugin.Identifier{Name: "uuid"}},
+					{Name: "parent_id", Table: authors, Type: &plugin.Identifier{Name: "uuid"}},
+					{Name: "metadata", NotNull: true, Table: authors, Type: &plugin.Identifier{Name: "jsonb"}},
+					{Name: "metadata", NotNull: true, Table: audit, Type: &plugin.Identifier{Name: "jsonb"}},
+					{Name: "bio", Table: authors, Type: &plugin.Identifier{Name: "text"}},
+				},
+			},
+		},
+		PluginOptions: []byte(`{
+			"filename": "",
+			"template": "{{ range (index .Queries 0).Columns }}{{ GoType . }},{{ TsType . }};{{ end }}",
+			"overrides": ` + overrides + `
+		}`),
+	}
+}
+
+func TestTypeOverrides(t *testing.T) {
+	testCases := map[string]struct {
+		engine    string
+		overrides string
+		columns   []*plugin.Column
+		expected  string
+	}{
+		"no overrides": {
+			engine:    "postgresql",
+			overrides: `[]`,
+			expected: "uuid.UUID,string;uuid.NullUUID,string | null;json.RawMessage,any;json.RawMessage,any;" +
+				"sql.NullString,string | null;",
+		},
+		"db_type": {
+			engine: "postgresql",
+			overrides: `[
+				{"db_type": "uuid", "go_type": "ids.ID", "ts_type": "Id"},
+				{"db_type": "pg_catalog.TEXT", "go_type": "*string"}
+			]`,
+			expected: "ids.ID,Id;ids.ID,Id;json.RawMessage,any;json.RawMessage,any;*string,string | null;",
+		},
+		"db_type nullable": {
+			engine: "postgresql",
+			overrides: `[
+				{"db_type": "uuid", "nullable": false, "go_type": "ids.ID"},
+				{"db_type": "uuid", "nullable": true, "go_type": "ids.NullID", "ts_type": "Id | null"}
+			]`,
+			expected: "ids.ID,string;ids.NullID,Id | null;json.RawMessage,any;json.RawMessage,any;" +
+				"sql.NullString,string | null;",
+		},
+		"column": {
+			engine: "postgresql",
+			overrides: `[
+				{"db_type": "jsonb", "go_type": "map[string]any"},
+				{"column": "authors.metadata", "go_type": "domain.Metadata", "ts_type": "Metadata"},
+				{"column": "audit.authors.metadata", "go_type": "audit.Metadata"}
+			]`,
+			expected: "uuid.UUID,string;uuid.NullUUID,string | null;domain.Metadata,Metadata;audit.Metadata,any;" +
+				"sql.NullString,string | null;",
+		},
+		"column with the default schema": {
+			engine: "postgresql",
+			overrides: `[
+				{"column": "public.authors.metadata", "go_type": "domain.Metadata"}
+			]`,
+			expected: "uuid.UUID,string;uuid.NullUUID,string | null;domain.Metadata,any;json.RawMessage,any;" +
+				"sql.NullString,string | null;",
+		},
+		"engine": {
+			engine: "postgresql",
+			overrides: `[
+				{"db_type": "uuid", "engine": "mysql", "go_type": "mysql.UUID"},
+				{"db_type": "text", "engine": "postgresql", "ts_type": "Text"}
+			]`,
+			expected: "uuid.UUID,string;uuid.NullUUID,string | null;json.RawMessage,any;json.RawMessage,any;" +
+				"sql.NullString,Text;",
+		},
+		"column alias": {
+			engine: "postgresql",
+			overrides: `[
+				{"column": "authors.id", "go_type": "ids.AuthorID"}
+			]`,
+			columns: []*plugin.Column{
+				{
+					Name:         "author_id",
+					OriginalName: "id",
+					NotNull:      true,
+					Table:        &plugin.Identifier{Name: "authors"},
+					Type:         &plugin.Identifier{Name: "uuid"},
+				},
+				{Name: "id", OriginalName: "author_id", NotNull: true, Table: &plugin.Identifier{Name: "authors"}},
+			},
+			expected: "ids.AuthorID,string;any,any;",
+		},
+		"db_type arrays and slices": {
+			engine: "postgresql",
+			overrides: `[
+				{"db_type": "uuid", "nullable": false, "go_type": "ids.ID", "ts_type": "Id"},
+				{"db_type": "uuid", "nullable": true, "go_type": "ids.NullID", "ts_type": "Id | null"}
+			]`,
+			columns: []*plugin.Column{
+				{Name: "ids", IsArray: true, Type: &plugin.Identifier{Name: "uuid"}},
+				{Name: "ids", NotNull: true, IsArray: true, ArrayDims: 2, Type: &plugin.Identifier{Name: "uuid"}},
+				{Name: "ids", IsSqlcSlice: true, Type: &plugin.Identifier{Name: "uuid"}},
+			},
+			expected: "[]ids.ID,Id[] | null;[][]ids.ID,Id[][];[]ids.ID,Id[];",
+		},
+		"column array": {
+			engine: "postgresql",
+			overrides: `[
+				{"column": "authors.tag_ids", "go_type": "ids.TagID", "ts_type": "TagId"}
+			]`,
+			columns: []*plugin.Column{
+				{
+					Name:    "tag_ids",
+					NotNull: true,
+					IsArray: true,
+					Table:   &plugin.Identifier{Name: "authors"},
+					Type:    &plugin.Identifier{Name: "uuid"},
+				},
+			},
+			expected: "[]ids.TagID,TagId[];",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			request := createOverridesTestGenerateRequest(testCase.engine, testCase.overrides)
+			if testCase.columns != nil {
+				request.Queries[0].Columns = testCase.columns
+			}
+
+			response, err := code.Generate(request)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, string(response.GetFiles()[0].GetContents()))
+		})
+	}
+}
+
+func TestTypeOverridesFailure(t *testing.T) {
+	testCases := map[string]struct {
+		overrides      string
+		expectedErrMsg string
+	}{
+		"missing db_type and column": {
+			overrides: `[{"go_type": "string"}]`,
+			expectedErrMsg: "the sqlc config 'sql[].codegen.options.overrides[0]' field must define either the " +
+				"'db_type' or the 'column' field",
+		},
+		"db_type and column": {
+			overrides: `[{"db_type": "text", "go_type": "string"}, {"db_type": "text", "column": "a.b"}]`,
+			expectedErrMsg: "the sqlc config 'sql[].codegen.options.overrides[1]' field must define either the " +
+				"'db_type' or the 'column' field",
+		},
+		"invalid column": {
+			overrides:      `[{"column": "authors", "go_type": "string"}]`,
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.overrides[0].column' field value \"authors\"",
+		},
+		"invalid engine": {
+			overrides:      `[{"db_type": "text", "engine": "oracle", "go_type": "string"}]`,
+			expectedErrMsg: "invalid sqlc config 'sql[].codegen.options.overrides[0].engine' field value \"oracle\"",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			_, err := code.Generate(createOverridesTestGenerateRequest("postgresql", testCase.overrides))
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package code
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+const (
+	// placeholderStyleDollar is the PostgreSQL `$1` numbered placeholder style.
+	placeholderStyleDollar = "dollar"
+	// placeholderStyleQmark is the MySQL and SQLite `?` positional placeholder style.
+	placeholderStyleQmark = "qmark"
+	// placeholderStyleNamed is the `:name` named placeholder style.
+	placeholderStyleNamed = "named"
+	// placeholderStyleAt is the `@name` named placeholder style, used by C# ADO.NET drivers.
+	placeholderStyleAt = "at"
+	// placeholderStylePyformat is the `%(name)s` named placeholder style, used by Python psycopg.
+	placeholderStylePyformat = "pyformat"
+)
+
+var placeholderStyles = []string{
+	placeholderStyleDollar, placeholderStyleQmark, placeholderStyleNamed, placeholderStyleAt, placeholderStylePyformat,
+}
+
+// placeholder is a query parameter placeholder found in a query text.
+type placeholder struct {
+	// start and end are the byte offsets of the placeholder in the query text.
+	start int
+	end   int
+	// number is the parameter number the placeholder refers to.
+	number int
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+func isDigitByte(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// skipUntil returns the offset after the first occurrence of end at or after the offset i, or the text length if
+// there is none.
+func skipUntil(text string, i int, end string) int {
+	if j := strings.Index(text[i:], end); j >= 0 {
+		return i + j + len(end)
+	}
+
+	return len(text)
+}
+
+// skipQuoted returns the offset after the closing quote of the quoted text that starts at the offset i, doubled quotes
+// are escaped quotes and, if backslashEscapes is true, backslashes escape the next byte.
+func skipQuoted(text string, i int, backslashEscapes bool) int {
+	quote := text[i]
+	for i++; i < len(text); i++ {
+		switch {
+		case backslashEscapes && text[i] == '\\':
+			i++
+		case text[i] == quote:
+			if i+1 < len(text) && text[i+1] == quote {
+				i++
+
+				continue
+			}
+
+			return i + 1
+		}
+	}
+
+	return len(text)
+}
+
+// findPlaceholders returns the parameter placeholders of a query text in order of occurrence.
+// String literals, quoted identifiers, comments and PostgreSQL dollar quoted strings are ignored.
+// PostgreSQL queries use `$1` placeholders, MySQL and SQLite queries use `?` or `?1` placeholders.
+func findPlaceholders(text string, engine string) []placeholder {
+	placeholders := []placeholder{}
+	position := 0
+	for i := 0; i < len(text); {
+		b := text[i]
+		switch {
+		case b == '\'':
+			// PostgreSQL only supports backslash escapes in `E'...'` strings.
+			backslashEscapes := engine == engineMySQL ||
+				(i > 0 && (text[i-1] == 'E' || text[i-1] == 'e') && (i == 1 || !isIdentifierByte(text[i-2])))
+			i = skipQuoted(text, i, backslashEscapes)
+		case b == '"' || (b == '`' && engine != enginePostgreSQL):
+			i = skipQuoted(text, i, false)
+		case b == '-' && strings.HasPrefix(text[i:], "--"):
+			i = skipUntil(text, i, "\n")
+		case b == '#' && engine == engineMySQL:
+			i = skipUntil(text, i, "\n")
+		case b == '/' && strings.HasPrefix(text[i:], "/*"):
+			i = skipUntil(text, i+2, "*/")
+		case b == '$' && engine == enginePostgreSQL && (i == 0 || !isIdentifierByte(text[i-1])):
+			j := i + 1
+			for j < len(text) && isDigitByte(text[j]) {
+				j++
+			}
+			if j > i+1 {
+				number, _ := strconv.Atoi(text[i+1 : j])
+				placeholders = append(placeholders, placeholder{start: i, end: j, number: number})
+				i = j
+
+				continue
+			}
+
+			// Dollar quoted strings: `$$...$$` or `$tag$...$tag$`.
+			for j < len(text) && isIdentifierByte(text[j]) {
+				j++
+			}
+			if j < len(text) && text[j] == '$' {
+				i = skipUntil(text, j+1, text[i:j+1])
+
+				continue
+			}
+			i++
+		case b == '?' && engine != enginePostgreSQL:
+			j := i + 1
+			for j < len(text) && isDigitByte(text[j]) {
+				j++
+			}
+
+			position++
+			number := position
+			if j > i+1 {
+				number, _ = strconv.Atoi(text[i+1 : j])
+			}
+			placeholders = append(placeholders, placeholder{start: i, end: j, number: number})
+			i = j
+		default:
+			i++
+		}
+	}
+
+	return placeholders
+}
+
+// getParameterNames returns the names of the query parameters by parameter number, used by the named placeholder
+// styles. The names are the `ParamsStruct` field `DBName`s so that different parameters never share a name.
+func getParameterNames(query *plugin.Query) map[int32]string {
+	names := map[int32]string{}
+	for i, field := range getParamsFields(query) {
+		names[query.GetParams()[i].GetNumber()] = field.DBName
+	}
+
+	return names
+}
+
+func findParameter(query *plugin.Query, number int) *plugin.Parameter {
+	for _, parameter := range query.GetParams() {
+		if int(parameter.GetNumber()) == number {
+			return parameter
+		}
+	}
+
+	return nil
+}
+
+func formatPlaceholder(style string, parameter *plugin.Parameter, name string) string {
+	switch style {
+	case placeholderStyleDollar:
+		return fmt.Sprintf("$%d", parameter.GetNumber())
+	case placeholderStyleQmark:
+		return "?"
+	case placeholderStyleNamed:
+		return ":" + name
+	case placeholderStyleAt:
+		return "@" + name
+	default:
+		return "%(" + name + ")s"
+	}
+}
+
+// isSlicePlaceholder reports whether the placeholder that ends at the offset end is the `?` of the `/*SLICE:name*/?`
+// marker of a `sqlc.slice(name)` parameter.
+func isSlicePlaceholder(text string, end int, parameter *plugin.Parameter) bool {
+	column := parameter.GetColumn()
+
+	return column.GetIsSqlcSlice() && strings.HasSuffix(text[:end], formatSliceMarker(column))
+}
+
+// rewritePlaceholders rewrites the placeholders of the query text into the given style, except the `?` of the
+// `sqlc.slice(name)` markers that are kept so that the `SliceMarker` and `SliceExpansion` functions still match.
+func rewritePlaceholders(query *plugin.Query, engine string, style string) (string, error) {
+	text := query.GetText()
+	names := getParameterNames(query)
+	buf := strings.Builder{}
+	last := 0
+	for _, placeholder := range findPlaceholders(text, engine) {
+		parameter := findParameter(query, placeholder.number)
+		if parameter == nil {
+			return "", fmt.Errorf(
+				"the query %q placeholder %q does not match any parameter",
+				query.GetName(), text[placeholder.start:placeholder.end],
+			)
+		}
+
+		literal := text[last:placeholder.start]
+		if style == placeholderStylePyformat {
+			// A literal `%` must be escaped when using the pyformat style.
+			literal = strings.ReplaceAll(literal, "%", "%%")
+		}
+
+		buf.WriteString(literal)
+		if isSlicePlaceholder(text, placeholder.end, parameter) {
+			// The `SliceExpansion` statements replace the whole `/*SLICE:name*/?` marker at runtime.
+			buf.WriteString(text[placeholder.start:placeholder.end])
+		} else {
+			buf.WriteString(formatPlaceholder(style, parameter, names[parameter.GetNumber()]))
+		}
+		last = placeholder.end
+	}
+
+	literal := text[last:]
+	if style == placeholderStylePyformat {
+		literal = strings.ReplaceAll(literal, "%", "%%")
+	}
+	buf.WriteString(literal)
+
+	return buf.String(), nil
+}
+
+// getRewritePlaceholdersFunction returns the `RewritePlaceholders query style [engine]` template function.
+// The engine defaults to the sqlc config 'sql[].engine' field.
+func getRewritePlaceholdersFunction(
+	request *plugin.GenerateRequest,
+) func(*plugin.Query, string, ...string) (string, error) {
+	return func(query *plugin.Query, style string, args ...string) (string, error) {
+		if query == nil {
+			return "", fmt.Errorf("RewritePlaceholders: the query is nil")
+		}
+
+		if len(args) > 1 {
+			return "", fmt.Errorf("RewritePlaceholders: expected at most 1 argument after the style, got %d", len(args))
+		}
+
+		engine, err := getEngine(request, args)
+		if err != nil {
+			return "", fmt.Errorf("RewritePlaceholders: %w", err)
+		}
+
+		switch style {
+		case placeholderStyleDollar, placeholderStyleQmark, placeholderStyleNamed, placeholderStyleAt,
+			placeholderStylePyformat:
+		default:
+			return "", fmt.Errorf("RewritePlaceholders: invalid style %q, must be one of: %q", style, placeholderStyles)
+		}
+
+		text, err := rewritePlaceholders(query, engine, style)
+		if err != nil {
+			return "", fmt.Errorf("RewritePlaceholders: %w", err)
+		}
+
+		return text, nil
+	}
+}
+
+// getPlaceholderParamsFunction returns the `PlaceholderParams query [engine]` template function that returns the
+// parameter of every placeholder in order of occurrence, a parameter used more than once is repeated.
+// It is required to pass the arguments of the positional "qmark" style.
+func getPlaceholderParamsFunction(
+	request *plugin.GenerateRequest,
+) func(*plugin.Query, ...string) ([]*plugin.Parameter, error) {
+	return func(query *plugin.Query, args ...string) ([]*plugin.Parameter, error) {
+		if query == nil {
+			return nil, fmt.Errorf("PlaceholderParams: the query is nil")
+		}
+
+		if len(args) > 1 {
+			return nil, fmt.Errorf("PlaceholderParams: expected at most 1 argument after the query, got %d", len(args))
+		}
+
+		engine, err := getEngine(request, args)
+		if err != nil {
+			return nil, fmt.Errorf("PlaceholderParams: %w", err)
+		}
+
+		parameters := []*plugin.Parameter{}
+		for _, placeholder := range findPlaceholders(query.GetText(), engine) {
+			parameter := findParameter(query, placeholder.number)
+			if parameter == nil {
+				return nil, fmt.Errorf(
+					"PlaceholderParams: the query %q placeholder %q does not match any parameter",
+					query.GetName(), query.GetText()[placeholder.start:placeholder.end],
+				)
+			}
+			parameters = append(parameters, parameter)
+		}
+
+		return parameters, nil
+	}
+}
@@ -0,0 +1,217 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func createPlaceholdersTestGenerateRequest(engine string, text string, template string) *plugin.GenerateRequest {
+	return &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: engine},
+		Queries: []*plugin.Query{
+			{
+				Name: "UpdateAuthor",
+				Text: text,
+				Params: []*plugin.Parameter{
+					{Number: 1, Column: &plugin.Column{Name: "id"}},
+					{Number: 2, Column: &plugin.Column{Name: "name"}},
+					{Number: 3, Column: &plugin.Column{}},
+				},
+			},
+		},
+		PluginOptions: []byte(`{
+			"filename": "",
+			"template": "` + jsonString(`{{ with index .Queries 0 }}`+template+`{{ end }}`) + `"
+		}`),
+	}
+}
+
+func TestRewritePlaceholders(t *testing.T) {
+	postgresqlText := `UPDATE authors SET name = $2, bio = $3, tag = '$1', note = $$ $1 $$ -- $1
+WHERE id = $1 /* $2 */ AND name LIKE '%' || $2 AND data ? 'key' AND a$1 = $tag$ $2 $tag$`
+	mysqlText := "UPDATE authors SET name = ?, bio = ?, tag = '?\\'?', `?` = \"?\" # ?\nWHERE id = ? -- ?"
+
+	testCases := map[string]struct {
+		engine   string
+		text     string
+		params   []*plugin.Parameter
+		template string
+		expected string
+	}{
+		"postgresql dollar": {
+			engine:   "postgresql",
+			text:     postgresqlText,
+			template: `{{ RewritePlaceholders . "dollar" }}`,
+			expected: postgresqlText,
+		},
+		"postgresql qmark": {
+			engine:   "postgresql",
+			text:     postgresqlText,
+			template: `{{ RewritePlaceholders . "qmark" }}`,
+			expected: `UPDATE authors SET name = ?, bio = ?, tag = '$1', note = $$ $1 $$ -- $1
+WHERE id = ? /* $2 */ AND name LIKE '%' || ? AND data ? 'key' AND a$1 = $tag$ $2 $tag$`,
+		},
+		"postgresql named": {
+			engine:   "postgresql",
+			text:     postgresqlText,
+			template: `{{ RewritePlaceholders . "named" }}`,
+			expected: `UPDATE authors SET name = :name, bio = :dollar_3, tag = '$1', note = $$ $1 $$ -- $1
+WHERE id = :id /* $2 */ AND name LIKE '%' || :name AND data ? 'key' AND a$1 = $tag$ $2 $tag$`,
+		},
+		"postgresql at": {
+			engine:   "postgresql",
+			text:     "SELECT * FROM authors WHERE id = $1 AND name = E'\\'$2'",
+			template: `{{ RewritePlaceholders . "at" }}`,
+			expected: "SELECT * FROM authors WHERE id = @id AND name = E'\\'$2'",
+		},
+		"postgresql pyformat": {
+			engine:   "postgresql",
+			text:     postgresqlText,
+			template: `{{ RewritePlaceholders . "pyformat" }}`,
+			expected: `UPDATE authors SET name = %(name)s, bio = %(dollar_3)s, tag = '$1', note = $$ $1 $$ -- $1
+WHERE id = %(id)s /* $2 */ AND name LIKE '%%' || %(name)s AND data ? 'key' AND a$1 = $tag$ $2 $tag$`,
+		},
+		"mysql named": {
+			engine:   "mysql",
+			text:     mysqlText,
+			template: `{{ RewritePlaceholders . "named" }}`,
+			expected: "UPDATE authors SET name = :id, bio = :name, tag = '?\\'?', `?` = \"?\" # ?\nWHERE id = :dollar_3 -- ?",
+		},
+		"mysql dollar": {
+			engine:   "mysql",
+			text:     mysqlText,
+			template: `{{ RewritePlaceholders . "dollar" }}`,
+			expected: "UPDATE authors SET name = $1, bio = $2, tag = '?\\'?', `?` = \"?\" # ?\nWHERE id = $3 -- ?",
+		},
+		"sqlite numbered": {
+			engine:   "sqlite",
+			text:     "UPDATE authors SET name = ?2 WHERE id = ?1",
+			template: `{{ RewritePlaceholders . "at" }}`,
+			expected: "UPDATE authors SET name = @name WHERE id = @id",
+		},
+		"engine argument": {
+			engine:   "postgresql",
+			text:     "SELECT ? FROM authors",
+			template: `{{ RewritePlaceholders . "dollar" "sqlite" }}`,
+			expected: "SELECT $1 FROM authors",
+		},
+		"named parameters on the same column": {
+			engine: "postgresql",
+			text:   "SELECT * FROM authors WHERE created_at > $1 AND created_at < $2 AND $2 > $3",
+			params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "created_at"}},
+				{Number: 2, Column: &plugin.Column{Name: "created_at"}},
+				{Number: 3, Column: &plugin.Column{Name: "created_at_2"}},
+			},
+			template: `{{ RewritePlaceholders . "named" }}`,
+			expected: "SELECT * FROM authors WHERE created_at > :created_at AND created_at < :created_at_2 AND " +
+				":created_at_2 > :created_at_2_2",
+		},
+		"mysql slice marker": {
+			engine: "mysql",
+			text:   "SELECT * FROM authors WHERE name = ? AND id IN (/*SLICE:ids*/?) AND bio = '/*SLICE:ids*/?'",
+			params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "name"}},
+				{Number: 2, Column: &plugin.Column{Name: "ids", IsSqlcSlice: true}},
+			},
+			template: `{{ RewritePlaceholders . "named" }}`,
+			expected: "SELECT * FROM authors WHERE name = :name AND id IN (/*SLICE:ids*/?) AND bio = '/*SLICE:ids*/?'",
+		},
+		"sqlite slice marker": {
+			engine: "sqlite",
+			text:   "SELECT * FROM authors WHERE id IN (/*SLICE:ids*/?) AND name = ?",
+			params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "ids", IsSqlcSlice: true}},
+				{Number: 2, Column: &plugin.Column{Name: "name"}},
+			},
+			template: `{{ RewritePlaceholders . "pyformat" }}|{{ RewritePlaceholders . "dollar" }}|` +
+				`{{ RewritePlaceholders . "at" }}`,
+			expected: "SELECT * FROM authors WHERE id IN (/*SLICE:ids*/?) AND name = %(name)s|" +
+				"SELECT * FROM authors WHERE id IN (/*SLICE:ids*/?) AND name = $2|" +
+				"SELECT * FROM authors WHERE id IN (/*SLICE:ids*/?) AND name = @name",
+		},
+		"slice marker of another parameter": {
+			engine: "mysql",
+			text:   "SELECT * FROM authors WHERE id IN (/*SLICE:ids*/?)",
+			params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "id"}},
+			},
+			template: `{{ RewritePlaceholders . "named" }}`,
+			expected: "SELECT * FROM authors WHERE id IN (/*SLICE:ids*/:id)",
+		},
+		"slice expansion after rewriting placeholders": {
+			engine: "mysql",
+			text:   "SELECT * FROM authors WHERE id IN (/*SLICE:ids*/?) AND name = ?",
+			params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "ids", IsSqlcSlice: true}},
+				{Number: 2, Column: &plugin.Column{Name: "name"}},
+			},
+			template: `{{ $text := RewritePlaceholders . "named" }}{{ range SliceParams . }}` +
+				`{{ contains (SliceMarker .) $text }}{{ end }}`,
+			expected: "true",
+		},
+		"placeholder params": {
+			engine:   "postgresql",
+			text:     "SELECT * FROM authors WHERE name = $2 OR bio = $2 OR id = $1",
+			template: `{{ range PlaceholderParams . }}{{ .Number }};{{ end }}`,
+			expected: "2;2;1;",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			request := createPlaceholdersTestGenerateRequest(testCase.engine, testCase.text, testCase.template)
+			if testCase.params != nil {
+				request.Queries[0].Params = testCase.params
+			}
+
+			response, err := code.Generate(request)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, string(response.GetFiles()[0].GetContents()))
+		})
+	}
+}
+
+func TestRewritePlaceholdersFailure(t *testing.T) {
+	testCases := map[string]struct {
+		text           string
+		template       string
+		expectedErrMsg string
+	}{
+		"invalid style": {
+			text:           "SELECT 1",
+			template:       `{{ RewritePlaceholders . "colon" }}`,
+			expectedErrMsg: "RewritePlaceholders: invalid style \"colon\"",
+		},
+		"invalid engine": {
+			text:           "SELECT 1",
+			template:       `{{ RewritePlaceholders . "named" "oracle" }}`,
+			expectedErrMsg: "RewritePlaceholders: invalid engine \"oracle\"",
+		},
+		"unknown parameter": {
+			text:           "SELECT $4",
+			template:       `{{ RewritePlaceholders . "named" }}`,
+			expectedErrMsg: "RewritePlaceholders: the query \"UpdateAuthor\" placeholder \"$4\" does not match any parameter",
+		},
+		"placeholder params unknown parameter": {
+			text:           "SELECT $4",
+			template:       `{{ PlaceholderParams . }}`,
+			expectedErrMsg: "PlaceholderParams: the query \"UpdateAuthor\" placeholder \"$4\" does not match any parameter",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			_, err := code.Generate(createPlaceholdersTestGenerateRequest("postgresql", testCase.text, testCase.template))
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package code
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+// sliceExpansions are the statements, per language, that replace the marker of a `sqlc.slice(name)` parameter in a
+// query text variable by one `?` placeholder per value, or by NULL when there are no values so that `IN (NULL)` matches
+// no rows. The statements are formatted with the query variable, the quoted marker and the values variable.
+// Only the first marker occurrence is replaced, the C# values variable must be a collection with a `Count` property
+// like a `List<T>`.
+var sliceExpansions = map[string]string{
+	"go": `if len(%[3]s) > 0 {
+	%[1]s = strings.Replace(%[1]s, %[2]s, strings.Repeat(",?", len(%[3]s))[1:], 1)
+} else {
+	%[1]s = strings.Replace(%[1]s, %[2]s, "NULL", 1)
+}`,
+	"typescript": `%[1]s = %[1]s.replace(%[2]s, %[3]s.length > 0 ? %[3]s.map(() => "?").join(",") : "NULL");`,
+	"python":     `%[1]s = %[1]s.replace(%[2]s, ",".join(["?"] * len(%[3]s)) if %[3]s else "NULL", 1)`,
+	"kotlin":     `%[1]s = %[1]s.replaceFirst(%[2]s, if (%[3]s.isNotEmpty()) %[3]s.joinToString(",") { "?" } else "NULL")`,
+	"rust": `let %[1]s = %[1]s.replacen(%[2]s, &if %[3]s.is_empty() { "NULL".to_string() } else { vec!["?"; ` +
+		`%[3]s.len()].join(",") }, 1);`,
+	"csharp": `%[1]s = new System.Text.RegularExpressions.Regex(System.Text.RegularExpressions.Regex.Escape(%[2]s))` +
+		`.Replace(%[1]s, %[3]s.Count > 0 ? string.Join(",", Enumerable.Repeat("?", %[3]s.Count)) : "NULL", 1);`,
+}
+
+var sliceExpansionLanguages = []string{"go", "typescript", "python", "kotlin", "rust", "csharp"}
+
+// getParamColumn returns the column of a query parameter or of a struct field column.
+func getParamColumn(function string, param any) (*plugin.Column, error) {
+	switch param := param.(type) {
+	case *plugin.Parameter:
+		return param.GetColumn(), nil
+	case *plugin.Column:
+		return param, nil
+	default:
+		return nil, fmt.Errorf("%s: expected a parameter or a column, got %T", function, param)
+	}
+}
+
+// getSliceColumn returns the column of a `sqlc.slice(name)` query parameter.
+func getSliceColumn(function string, param any) (*plugin.Column, error) {
+	column, err := getParamColumn(function, param)
+	if err != nil {
+		return nil, err
+	}
+
+	if !column.GetIsSqlcSlice() {
+		return nil, fmt.Errorf("%s: the parameter %q is not a sqlc.slice parameter", function, column.GetName())
+	}
+
+	return column, nil
+}
+
+// formatSliceMarker returns the text sqlc writes in the query text in place of a `sqlc.slice(name)` parameter.
+func formatSliceMarker(column *plugin.Column) string {
+	return "/*SLICE:" + column.GetName() + "*/?"
+}
+
+// sliceParams is the `SliceParams query` template function that returns the `sqlc.slice(name)` parameters of a query.
+func sliceParams(query *plugin.Query) []*plugin.Parameter {
+	params := []*plugin.Parameter{}
+	for _, param := range query.GetParams() {
+		if param.GetColumn().GetIsSqlcSlice() {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// sliceMarker is the `SliceMarker param` template function that returns the `/*SLICE:name*/?` marker of a
+// `sqlc.slice(name)` parameter.
+func sliceMarker(param any) (string, error) {
+	column, err := getSliceColumn("SliceMarker", param)
+	if err != nil {
+		return "", err
+	}
+
+	return formatSliceMarker(column), nil
+}
+
+// sliceExpansion is the `SliceExpansion language param queryVariable valuesVariable` template function that returns
+// the statements of a language that expand the marker of a `sqlc.slice(name)` parameter in the query text variable
+// into one `?` placeholder per value of the values variable.
+func sliceExpansion(language string, param any, queryVariable string, valuesVariable string) (string, error) {
+	expansion, ok := sliceExpansions[language]
+	if !ok {
+		return "", fmt.Errorf("SliceExpansion: invalid language %q, must be one of: %q", language, sliceExpansionLanguages)
+	}
+
+	column, err := getSliceColumn("SliceExpansion", param)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(expansion, queryVariable, strconv.Quote(formatSliceMarker(column)), valuesVariable), nil
+}
+
+// isNarg is the `IsNarg param` template function that reports whether a parameter is a named parameter with a
+// nullable type. The plugin protocol does not tell `sqlc.narg(name)` apart from a `sqlc.arg(name)` or `@name` parameter
+// on a nullable column, so both are reported.
+func isNarg(param any) (bool, error) {
+	column, err := getParamColumn("IsNarg", param)
+	if err != nil {
+		return false, err
+	}
+
+	return column.GetIsNamedParam() && !column.GetNotNull(), nil
+}
@@ -0,0 +1,119 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func createSliceTestGenerateRequest(template string) *plugin.GenerateRequest {
+	return &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "mysql"},
+		Queries: []*plugin.Query{
+			{
+				Name: "ListAuthorsByIDs",
+				Text: "SELECT id FROM authors WHERE id IN (/*SLICE:ids*/?) AND (name = ? OR ? IS NULL) " +
+					"AND book_id IN (/*SLICE:book_ids*/?)",
+				Params: []*plugin.Parameter{
+					{Number: 1, Column: &plugin.Column{Name: "ids", NotNull: true, IsSqlcSlice: true}},
+					{Number: 2, Column: &plugin.Column{Name: "name", IsNamedParam: true}},
+					{Number: 3, Column: &plugin.Column{Name: "name", NotNull: true, IsNamedParam: true}},
+					{Number: 4, Column: &plugin.Column{Name: "book_ids", NotNull: true, IsSqlcSlice: true}},
+				},
+			},
+		},
+		PluginOptions: []byte(`{
+			"filename": "queries.txt",
+			"template": "` + jsonString(template) + `"
+		}`),
+	}
+}
+
+func TestSliceParams(t *testing.T) {
+	testCases := map[string]struct {
+		template         string
+		expectedContents string
+		expectedErrMsg   string
+	}{
+		"SliceParams": {
+			template:         `{{ range SliceParams (index .Queries 0) }}{{ .Number }}:{{ .Column.Name }};{{ end }}`,
+			expectedContents: "1:ids;4:book_ids;",
+		},
+		"SliceMarker": {
+			template: `{{ range SliceParams (index .Queries 0) }}{{ SliceMarker . }} {{ SliceMarker .Column }};` +
+				`{{ end }}`,
+			expectedContents: "/*SLICE:ids*/? /*SLICE:ids*/?;/*SLICE:book_ids*/? /*SLICE:book_ids*/?;",
+		},
+		"SliceExpansion go": {
+			template: `{{ SliceExpansion "go" (index (index .Queries 0).Params 0) "query" "arg.IDs" }}`,
+			expectedContents: `if len(arg.IDs) > 0 {
+	query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.IDs))[1:], 1)
+} else {
+	query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+}`,
+		},
+		"SliceExpansion typescript": {
+			template: `{{ SliceExpansion "typescript" (index (index .Queries 0).Params 0) "sql" "args.ids" }}`,
+			expectedContents: `sql = sql.replace("/*SLICE:ids*/?", args.ids.length > 0 ? args.ids.map(() => "?").join(",") : ` +
+				`"NULL");`,
+		},
+		"SliceExpansion python": {
+			template: `{{ SliceExpansion "python" (index (index .Queries 0).Params 3) "sql" "book_ids" }}`,
+			expectedContents: `sql = sql.replace("/*SLICE:book_ids*/?", ",".join(["?"] * len(book_ids)) if book_ids ` +
+				`else "NULL", 1)`,
+		},
+		"SliceExpansion kotlin": {
+			template: `{{ SliceExpansion "kotlin" (index (index .Queries 0).Params 0) "sql" "ids" }}`,
+			expectedContents: `sql = sql.replaceFirst("/*SLICE:ids*/?", if (ids.isNotEmpty()) ids.joinToString(",") { "?" } ` +
+				`else "NULL")`,
+		},
+		"SliceExpansion rust": {
+			template: `{{ SliceExpansion "rust" (index (index .Queries 0).Params 0) "sql" "ids" }}`,
+			expectedContents: `let sql = sql.replacen("/*SLICE:ids*/?", &if ids.is_empty() { "NULL".to_string() } else ` +
+				`{ vec!["?"; ids.len()].join(",") }, 1);`,
+		},
+		"SliceExpansion csharp": {
+			template: `{{ SliceExpansion "csharp" (index (index .Queries 0).Params 0) "sql" "ids" }}`,
+			expectedContents: `sql = new System.Text.RegularExpressions.Regex(System.Text.RegularExpressions.Regex.Escape(` +
+				`"/*SLICE:ids*/?")).Replace(sql, ids.Count > 0 ? string.Join(",", Enumerable.Repeat("?", ids.Count)) : ` +
+				`"NULL", 1);`,
+		},
+		"IsNarg": {
+			template:         `{{ range (index .Queries 0).Params }}{{ .Column.Name }}={{ IsNarg . }};{{ end }}`,
+			expectedContents: "ids=false;name=true;name=false;book_ids=false;",
+		},
+		"SliceMarker not a slice": {
+			template:       `{{ SliceMarker (index (index .Queries 0).Params 1) }}`,
+			expectedErrMsg: `SliceMarker: the parameter "name" is not a sqlc.slice parameter`,
+		},
+		"SliceMarker invalid argument": {
+			template:       `{{ SliceMarker "ids" }}`,
+			expectedErrMsg: "SliceMarker: expected a parameter or a column, got string",
+		},
+		"SliceExpansion invalid language": {
+			template: `{{ SliceExpansion "java" (index (index .Queries 0).Params 0) "sql" "ids" }}`,
+			expectedErrMsg: `SliceExpansion: invalid language "java", must be one of: ` +
+				`["go" "typescript" "python" "kotlin" "rust" "csharp"]`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			response, err := code.Generate(createSliceTestGenerateRequest(testCase.template))
+			if testCase.expectedErrMsg != "" {
+				assert.ErrorContains(t, err, testCase.expectedErrMsg)
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testCase.expectedContents, string(response.Files[0].Contents))
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package code
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/huandu/xstrings"
+	"github.com/jinzhu/inflection"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+// structInitialisms are the struct field name parts written in upper case, like sqlc-gen-go does.
+var structInitialisms = map[string]bool{"id": true}
+
+// structSkippedSchemas are the catalog schemas without table model structs.
+var structSkippedSchemas = []string{"pg_catalog", "information_schema"}
+
+// templateStruct describes a struct generated from a table or from the columns or parameters of a query, following
+// the sqlc-gen-go naming rules.
+type templateStruct struct {
+	// Name is the struct name, for example "Author", "GetAuthorRow" or "CreateAuthorParams".
+	Name string
+	// Table is the table of a table model struct, nil for the structs of a query.
+	Table *plugin.Identifier
+	// Fields are the struct fields in the column or parameter order.
+	Fields []*templateStructField
+	// Emit is true for the structs of a query that must be declared by the template, table model structs are expected
+	// to be declared once per table.
+	Emit bool
+}
+
+// templateStructField is a field of a templateStruct.
+type templateStructField struct {
+	// Name is the unique field name, for example "AuthorID", or "Name_2" when two columns are named "name".
+	Name string
+	// DBName is the unique column name, usable as a struct tag, for example "author_id" or "name_2".
+	DBName string
+	Column *plugin.Column
+	// Embed is the table model struct of a `sqlc.embed(table)` column, nil for the other columns.
+	Embed *templateStruct
+}
+
+// structName returns the struct or field name of a snake case name, like sqlc-gen-go does: "author_id" becomes
+// "AuthorID" and a name starting with a digit is prefixed with an underscore.
+func structName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		return '_'
+	}, name)
+
+	out := strings.Builder{}
+	for _, part := range strings.Split(name, "_") {
+		if structInitialisms[part] {
+			out.WriteString(strings.ToUpper(part))
+			continue
+		}
+
+		if r, size := utf8.DecodeRuneInString(part); size > 0 {
+			out.WriteRune(unicode.ToUpper(r))
+			out.WriteString(part[size:])
+		}
+	}
+
+	if r, _ := utf8.DecodeRuneInString(out.String()); unicode.IsDigit(r) {
+		return "_" + out.String()
+	}
+
+	return out.String()
+}
+
+// singular returns the singular form of an English plural table name, for example "authors" becomes "author" and
+// "categories" becomes "category", with the same rules and exceptions as sqlc-gen-go.
+func singular(name string) string {
+	switch strings.ToLower(name) {
+	case "campus", "meta", "metadata":
+		return name
+	case "calories":
+		return "calorie"
+	case "waves":
+		return "wave"
+	default:
+		return inflection.Singular(name)
+	}
+}
+
+// newStructFields returns the fields of a struct of columns, the duplicated field names get a numeric suffix.
+// The suffix is incremented until both the field name and the column name are unique, for example the "name", "name"
+// and "name_2" columns become the "name", "name_2" and "name_2_2" fields.
+func newStructFields(names []string, columns []*plugin.Column) []*templateStructField {
+	seen := map[string]int{}
+	usedNames := map[string]bool{}
+	usedDBNames := map[string]bool{}
+	fields := make([]*templateStructField, 0, len(columns))
+	for i, column := range columns {
+		name := structName(names[i])
+		dbName := names[i]
+		for suffix := max(seen[structName(names[i])]+1, 2); usedNames[name] || usedDBNames[dbName]; suffix++ {
+			name = fmt.Sprintf("%s_%d", structName(names[i]), suffix)
+			dbName = fmt.Sprintf("%s_%d", names[i], suffix)
+		}
+		seen[structName(names[i])]++
+		usedNames[name] = true
+		usedDBNames[dbName] = true
+
+		fields = append(fields, &templateStructField{Name: name, DBName: dbName, Column: column})
+	}
+
+	return fields
+}
+
+// getTableStructs returns the model struct of every catalog table.
+func getTableStructs(request *plugin.GenerateRequest) []*templateStruct {
+	structs := []*templateStruct{}
+	for _, schema := range request.GetCatalog().GetSchemas() {
+		if slices.Contains(structSkippedSchemas, schema.GetName()) {
+			continue
+		}
+
+		for _, table := range schema.GetTables() {
+			name := table.GetRel().GetName()
+			if schema.GetName() != request.GetCatalog().GetDefaultSchema() {
+				name = schema.GetName() + "_" + name
+			}
+
+			names := make([]string, 0, len(table.GetColumns()))
+			for _, column := range table.GetColumns() {
+				names = append(names, column.GetName())
+			}
+
+			structs = append(structs, &templateStruct{
+				Name:   structName(singular(name)),
+				Table:  &plugin.Identifier{Schema: schema.GetName(), Name: table.GetRel().GetName()},
+				Fields: newStructFields(names, table.GetColumns()),
+			})
+		}
+	}
+
+	return structs
+}
+
+// sameTable reports whether two table identifiers are the same table, identifiers without a schema belong to the
+// catalog default schema.
+func sameTable(request *plugin.GenerateRequest, a *plugin.Identifier, b *plugin.Identifier) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	return cmp.Or(a.GetSchema(), request.GetCatalog().GetDefaultSchema()) ==
+		cmp.Or(b.GetSchema(), request.GetCatalog().GetDefaultSchema()) && a.GetName() == b.GetName()
+}
+
+// matchesColumns reports whether the result columns of a query have the same names, types and table of the fields of
+// a table model struct, in the same order.
+func (s *templateStruct) matchesColumns(request *plugin.GenerateRequest, columns []*plugin.Column) bool {
+	if len(s.Fields) != len(columns) {
+		return false
+	}
+
+	for i, field := range s.Fields {
+		column := columns[i]
+		if column.GetEmbedTable() != nil || field.Name != structName(getResultColumnName(column, i)) ||
+			getColumnTypeName(field.Column) != getColumnTypeName(column) ||
+			field.Column.GetNotNull() != column.GetNotNull() ||
+			field.Column.GetIsArray() != column.GetIsArray() ||
+			!sameTable(request, s.Table, column.GetTable()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getResultColumnName returns the name of a result column, unnamed columns are named after their position.
+func getResultColumnName(column *plugin.Column, i int) string {
+	if column.GetName() != "" {
+		return column.GetName()
+	}
+
+	return fmt.Sprintf("column_%d", i+1)
+}
+
+// getResultStruct returns the struct of the result columns of a query: the model struct of a table when the columns
+// match the table columns or a new "<Query>Row" struct. It returns nil for the queries without result columns or with
+// a single result column that is not a `sqlc.embed(table)` column.
+// The `sqlc.embed(table)` columns are fields named after the table model struct, with the model struct as `Embed`.
+func getResultStruct(request *plugin.GenerateRequest, query *plugin.Query) *templateStruct {
+	columns := query.GetColumns()
+	if len(columns) == 0 || (len(columns) == 1 && columns[0].GetEmbedTable() == nil) {
+		return nil
+	}
+
+	tableStructs := getTableStructs(request)
+	for _, tableStruct := range tableStructs {
+		if tableStruct.matchesColumns(request, columns) {
+			return tableStruct
+		}
+	}
+
+	names := make([]string, 0, len(columns))
+	embeds := make([]*templateStruct, 0, len(columns))
+	for i, column := range columns {
+		embed := findEmbedStruct(request, tableStructs, column)
+		embeds = append(embeds, embed)
+
+		if embed != nil {
+			names = append(names, xstrings.ToSnakeCase(embed.Name))
+			continue
+		}
+		names = append(names, getResultColumnName(column, i))
+	}
+
+	fields := newStructFields(names, columns)
+	for i, field := range fields {
+		field.Embed = embeds[i]
+	}
+
+	return &templateStruct{Name: query.GetName() + "Row", Fields: fields, Emit: true}
+}
+
+// findEmbedStruct returns the table model struct of a `sqlc.embed(table)` column or nil for the other columns.
+func findEmbedStruct(
+	request *plugin.GenerateRequest, tableStructs []*templateStruct, column *plugin.Column,
+) *templateStruct {
+	if column.GetEmbedTable() == nil {
+		return nil
+	}
+
+	for _, tableStruct := range tableStructs {
+		if sameTable(request, tableStruct.Table, column.GetEmbedTable()) {
+			return tableStruct
+		}
+	}
+
+	return nil
+}
+
+// getParamsFields returns the fields of the parameters of a query in the parameter order, named after the parameter
+// column or "dollar_<number>" for the parameters without a name. The duplicated names get a numeric suffix, for
+// example "created_at" and "created_at_2".
+func getParamsFields(query *plugin.Query) []*templateStructField {
+	params := query.GetParams()
+	names := make([]string, 0, len(params))
+	columns := make([]*plugin.Column, 0, len(params))
+	for _, param := range params {
+		name := param.GetColumn().GetName()
+		if name == "" {
+			name = fmt.Sprintf("dollar_%d", param.GetNumber())
+		}
+		names = append(names, name)
+		columns = append(columns, param.GetColumn())
+	}
+
+	return newStructFields(names, columns)
+}
+
+// getParamsStruct returns the "<Query>Params" struct of the parameters of a query. It returns nil for the queries
+// without parameters or with a single parameter, except for the `:copyfrom` queries that always use a struct.
+func getParamsStruct(query *plugin.Query) *templateStruct {
+	params := query.GetParams()
+	if len(params) == 0 || (len(params) == 1 && query.GetCmd() != commandCopyFrom) {
+		return nil
+	}
+
+	return &templateStruct{Name: query.GetName() + "Params", Fields: getParamsFields(query), Emit: true}
+}
+
+// TableStructs returns the model struct of every catalog table.
+func (r *requestTemplateData) TableStructs() []*templateStruct {
+	return getTableStructs(r.GenerateRequest)
+}
+
+// ResultStruct returns the struct of the result columns of a query, see getResultStruct.
+func (r *requestTemplateData) ResultStruct(query *plugin.Query) *templateStruct {
+	return getResultStruct(r.GenerateRequest, query)
+}
+
+// ParamsStruct returns the struct of the parameters of a query, see getParamsStruct.
+func (r *requestTemplateData) ParamsStruct(query *plugin.Query) *templateStruct {
+	return getParamsStruct(query)
+}
+
+// ResultStruct returns the struct of the query result columns, see getResultStruct.
+func (q *queryTemplateData) ResultStruct() *templateStruct {
+	return getResultStruct(q.Request.GenerateRequest, q.Query)
+}
+
+// ParamsStruct returns the struct of the query parameters, see getParamsStruct.
+func (q *queryTemplateData) ParamsStruct() *templateStruct {
+	return getParamsStruct(q.Query)
+}
@@ -0,0 +1,267 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func createStructTestGenerateRequest(options string) *plugin.GenerateRequest {
+	authors := &plugin.Identifier{Name: "authors"}
+	categories := &plugin.Identifier{Schema: "public", Name: "categories"}
+	int8 := &plugin.Identifier{Name: "int8"}
+	text := &plugin.Identifier{Name: "text"}
+
+	return &plugin.GenerateRequest{
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{
+				{
+					Name: "public",
+					Tables: []*plugin.Table{
+						{
+							Rel: authors,
+							Columns: []*plugin.Column{
+								{Name: "id", NotNull: true, Type: &plugin.Identifier{Schema: "pg_catalog", Name: "int8"}},
+								{Name: "name", NotNull: true, Type: text},
+								{Name: "bio", Type: text},
+							},
+						},
+						{Rel: categories, Columns: []*plugin.Column{{Name: "id", NotNull: true, Type: int8}}},
+					},
+				},
+				{
+					Name:   "archive",
+					Tables: []*plugin.Table{{Rel: &plugin.Identifier{Schema: "archive", Name: "addresses"}}},
+				},
+				{
+					Name:   "pg_catalog",
+					Tables: []*plugin.Table{{Rel: &plugin.Identifier{Schema: "pg_catalog", Name: "pg_class"}}},
+				},
+			},
+		},
+		Queries: []*plugin.Query{
+			{
+				Name: "GetAuthor",
+				Cmd:  ":one",
+				Columns: []*plugin.Column{
+					{Name: "id", NotNull: true, Table: authors, Type: int8},
+					{Name: "name", NotNull: true, Table: authors, Type: text},
+					{Name: "bio", Table: authors, Type: text},
+				},
+				Params: []*plugin.Parameter{{Number: 1, Column: &plugin.Column{Name: "id", NotNull: true, Type: int8}}},
+			},
+			{
+				Name: "ListAuthorNames",
+				Cmd:  ":many",
+				Columns: []*plugin.Column{
+					{Name: "id", NotNull: true, Table: authors, Type: int8},
+					{Name: "name", NotNull: true, Table: authors, Type: text},
+					{Name: "name", Table: categories, Type: text},
+					{Name: "", NotNull: true, Type: int8},
+				},
+				Params: []*plugin.Parameter{
+					{Number: 1, Column: &plugin.Column{Name: "name", NotNull: true, Type: text}},
+					{Number: 2, Column: &plugin.Column{Name: "name", NotNull: true, Type: text}},
+					{Number: 3, Column: &plugin.Column{NotNull: true, Type: int8}},
+				},
+			},
+			{
+				Name:    "CountAuthors",
+				Cmd:     ":one",
+				Columns: []*plugin.Column{{Name: "count", NotNull: true, Type: int8}},
+			},
+			{
+				Name:   "CopyAuthors",
+				Cmd:    ":copyfrom",
+				Params: []*plugin.Parameter{{Number: 1, Column: &plugin.Column{Name: "name", NotNull: true, Type: text}}},
+			},
+			{
+				Name: "GetAuthorBio",
+				Cmd:  ":one",
+				Columns: []*plugin.Column{
+					{Name: "id", NotNull: true, Table: authors, Type: int8},
+					{Name: "name", NotNull: true, Table: authors, Type: text},
+					{Name: "bio", NotNull: true, Table: authors, Type: text},
+				},
+			},
+		},
+		PluginOptions: []byte(options),
+	}
+}
+
+// structTestTemplate prints a struct with the `Name(Emit)[Field:DBName:Type,...]` format.
+const structTestTemplate = `{{ define "struct" }}{{ with . }}{{ .Name }}({{ .Emit }})[` +
+	`{{ range $i, $f := .Fields }}{{ if $i }},{{ end }}{{ .Name }}:{{ .DBName }}:{{ .Column.Type.Name }}{{ end }}]` +
+	`{{ else }}none{{ end }}{{ end }}`
+
+func TestStructs(t *testing.T) {
+	testCases := map[string]struct {
+		mode             string
+		template         string
+		expectedContents []string
+	}{
+		"TableStructs": {
+			template: `{{ range .TableStructs }}{{ template "struct" . }} {{ .Table.Schema }}.{{ .Table.Name }};{{ end }}`,
+			expectedContents: []string{
+				"Author(false)[ID:id:int8,Name:name:text,Bio:bio:text] public.authors;" +
+					"Category(false)[ID:id:int8] public.categories;" +
+					"ArchiveAddress(false)[] archive.addresses;",
+			},
+		},
+		"ResultStruct": {
+			template: `{{ range .Queries }}{{ .Name }}={{ template "struct" ($.ResultStruct .) }};{{ end }}`,
+			expectedContents: []string{
+				"GetAuthor=Author(false)[ID:id:int8,Name:name:text,Bio:bio:text];" +
+					"ListAuthorNames=ListAuthorNamesRow(true)[ID:id:int8,Name:name:text,Name_2:name_2:text," +
+					"Column4:column_4:int8];" +
+					"CountAuthors=none;" +
+					"CopyAuthors=none;" +
+					"GetAuthorBio=GetAuthorBioRow(true)[ID:id:int8,Name:name:text,Bio:bio:text];",
+			},
+		},
+		"ParamsStruct": {
+			template: `{{ range .Queries }}{{ .Name }}={{ template "struct" ($.ParamsStruct .) }};{{ end }}`,
+			expectedContents: []string{
+				"GetAuthor=none;" +
+					"ListAuthorNames=ListAuthorNamesParams(true)[Name:name:text,Name_2:name_2:text,Dollar3:dollar_3:int8];" +
+					"CountAuthors=none;" +
+					"CopyAuthors=CopyAuthorsParams(true)[Name:name:text];" +
+					"GetAuthorBio=none;",
+			},
+		},
+		"per-query mode": {
+			mode:     "per-query",
+			template: `{{ template "struct" .ResultStruct }} {{ template "struct" .ParamsStruct }}`,
+			expectedContents: []string{
+				"Author(false)[ID:id:int8,Name:name:text,Bio:bio:text] none",
+				"ListAuthorNamesRow(true)[ID:id:int8,Name:name:text,Name_2:name_2:text,Column4:column_4:int8] " +
+					"ListAuthorNamesParams(true)[Name:name:text,Name_2:name_2:text,Dollar3:dollar_3:int8]",
+				"none none",
+				"none CopyAuthorsParams(true)[Name:name:text]",
+				"GetAuthorBioRow(true)[ID:id:int8,Name:name:text,Bio:bio:text] none",
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			mode, filename := "single", "queries.txt"
+			if testCase.mode != "" {
+				mode, filename = testCase.mode, "{{ .Name }}.txt"
+			}
+
+			response, err := code.Generate(createStructTestGenerateRequest(`{
+				"mode": "` + mode + `",
+				"filename": "` + filename + `",
+				"template": "` + jsonString(structTestTemplate+testCase.template) + `"
+			}`))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			contents := []string{}
+			for _, file := range response.Files {
+				contents = append(contents, string(file.Contents))
+			}
+			assert.Equal(t, testCase.expectedContents, contents)
+		})
+	}
+}
+
+func TestEmbedStructs(t *testing.T) {
+	// embedTestTemplate prints the result struct fields with the embedded struct fields in brackets.
+	embedTestTemplate := `{{ range .Queries }}{{ .Name }}={{ with $.ResultStruct . }}{{ .Name }}:` +
+		`{{ range .Fields }} {{ .Name }}:{{ .DBName }}{{ with .Embed }}[{{ .Name }}:{{ .Table.Name }}` +
+		`{{ range .Fields }} {{ .Name }}{{ end }}]{{ end }}{{ end }}{{ else }}none{{ end }};{{ end }}`
+
+	request := createStructTestGenerateRequest(`{
+		"filename": "queries.txt",
+		"template": "` + jsonString(embedTestTemplate) + `"
+	}`)
+	authors := &plugin.Identifier{Name: "authors"}
+	categories := &plugin.Identifier{Schema: "public", Name: "categories"}
+	request.Queries = []*plugin.Query{
+		{
+			Name: "ListAuthorCategories",
+			Columns: []*plugin.Column{
+				{Name: "authors", EmbedTable: authors},
+				{Name: "categories", EmbedTable: &plugin.Identifier{Name: "categories"}},
+				{Name: "id", NotNull: true, Table: categories, Type: &plugin.Identifier{Name: "int8"}},
+			},
+		},
+		{
+			Name:    "GetEmbeddedAuthor",
+			Columns: []*plugin.Column{{Name: "authors", EmbedTable: authors}},
+		},
+		{
+			Name: "ListAuthorPairs",
+			Columns: []*plugin.Column{
+				{Name: "authors", EmbedTable: authors},
+				{Name: "authors", EmbedTable: authors},
+			},
+		},
+	}
+
+	response, err := code.Generate(request)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(
+		t,
+		"ListAuthorCategories=ListAuthorCategoriesRow: Author:author[Author:authors ID Name Bio] "+
+			"Category:category[Category:categories ID] ID:id;"+
+			"GetEmbeddedAuthor=GetEmbeddedAuthorRow: Author:author[Author:authors ID Name Bio];"+
+			"ListAuthorPairs=ListAuthorPairsRow: Author:author[Author:authors ID Name Bio] "+
+			"Author_2:author_2[Author:authors ID Name Bio];",
+		string(response.Files[0].Contents),
+	)
+}
+
+func TestTableStructNames(t *testing.T) {
+	testCases := map[string]string{
+		"authors":    "Author",
+		"categories": "Category",
+		"houses":     "House",
+		"warehouses": "Warehouse",
+		"movies":     "Movie",
+		// sqlc-gen-go also names the "caches" table model struct "Cach".
+		"caches":    "Cach",
+		"cookies":   "Cookie",
+		"people":    "Person",
+		"statuses":  "Status",
+		"campus":    "Campus",
+		"calories":  "Calorie",
+		"waves":     "Wave",
+		"metadata":  "Metadata",
+		"user_data": "UserDatum",
+	}
+
+	for table, expected := range testCases {
+		t.Run(table, func(t *testing.T) {
+			response, err := code.Generate(&plugin.GenerateRequest{
+				Catalog: &plugin.Catalog{
+					DefaultSchema: "public",
+					Schemas: []*plugin.Schema{
+						{Name: "public", Tables: []*plugin.Table{{Rel: &plugin.Identifier{Name: table}}}},
+					},
+				},
+				PluginOptions: []byte(`{
+					"filename": "queries.txt",
+					"template": "{{ range .TableStructs }}{{ .Name }}{{ end }}"
+				}`),
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, expected, string(response.Files[0].Contents))
+		})
+	}
+}
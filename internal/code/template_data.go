@@ -0,0 +1,145 @@
+package code
+
+import (
+	"fmt"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+const (
+	// modeSingle renders the template once, with the GenerateRequest as the root data object, into a single file.
+	modeSingle = "single"
+	// modePerQuery renders the template once per query, with a queryTemplateData as the root data object.
+	modePerQuery = "per-query"
+	// modePerTable renders the template once per catalog table, with a tableTemplateData as the root data object.
+	modePerTable = "per-table"
+	// modePerEnum renders the template once per catalog enum, with an enumTemplateData as the root data object.
+	modePerEnum = "per-enum"
+)
+
+var modes = []string{modeSingle, modePerQuery, modePerTable, modePerEnum}
+
+// groupByQueryFile groups the queries by the SQL file they were defined in and renders the template once per group.
+const groupByQueryFile = "query_file"
+
+// queryTemplateData is the root data object of the templates rendered in the "per-query" mode.
+// The query fields are available at the root (`{{ .Name }}`) and the full request via `{{ .Request }}`.
+type queryTemplateData struct {
+	*plugin.Query
+	Request *requestTemplateData
+}
+
+// queryFileTemplateData is the root data object of the templates rendered when grouping by "query_file".
+// It is a copy of the GenerateRequest where `{{ .Queries }}` only contains the queries of the `{{ .Filename }}` SQL
+// file, the full request is available via `{{ .Request }}`.
+type queryFileTemplateData struct {
+	*requestTemplateData
+	Filename string
+	Request  *requestTemplateData
+}
+
+// tableTemplateData is the root data object of the templates rendered in the "per-table" mode.
+type tableTemplateData struct {
+	*plugin.Table
+	Schema  *plugin.Schema
+	Request *requestTemplateData
+}
+
+// enumTemplateData is the root data object of the templates rendered in the "per-enum" mode.
+type enumTemplateData struct {
+	*plugin.Enum
+	Schema  *plugin.Schema
+	Request *requestTemplateData
+}
+
+// getQueryFileTemplateData returns one root data object per SQL file, in the order the files first appear in the
+// request queries.
+func getQueryFileTemplateData(requestData *requestTemplateData) []any {
+	request := requestData.GenerateRequest
+	filenames := []string{}
+	queriesByFilename := map[string][]*plugin.Query{}
+	for _, query := range request.GetQueries() {
+		if _, ok := queriesByFilename[query.GetFilename()]; !ok {
+			filenames = append(filenames, query.GetFilename())
+		}
+		queriesByFilename[query.GetFilename()] = append(queriesByFilename[query.GetFilename()], query)
+	}
+
+	data := make([]any, 0, len(filenames))
+	for _, filename := range filenames {
+		fileRequest := &plugin.GenerateRequest{
+			Settings:      request.GetSettings(),
+			Catalog:       request.GetCatalog(),
+			Queries:       queriesByFilename[filename],
+			SqlcVersion:   request.GetSqlcVersion(),
+			PluginOptions: request.GetPluginOptions(),
+			GlobalOptions: request.GetGlobalOptions(),
+		}
+
+		data = append(data, &queryFileTemplateData{
+			requestTemplateData: &requestTemplateData{GenerateRequest: fileRequest, strict: requestData.strict},
+			Filename:            filename,
+			Request:             requestData,
+		})
+	}
+
+	return data
+}
+
+// getTemplateData returns the root data objects of each file to be generated for the given mode and grouping.
+// The mode and grouping are expected to be validated by outputOptions.validate.
+func getTemplateData(request *plugin.GenerateRequest, mode string, groupBy string, strict bool) ([]any, error) {
+	requestData := &requestTemplateData{GenerateRequest: request, strict: strict}
+	if groupBy == groupByQueryFile {
+		return getQueryFileTemplateData(requestData), nil
+	}
+
+	switch mode {
+	case modeSingle:
+		return []any{requestData}, nil
+	case modePerQuery:
+		data := make([]any, 0, len(request.GetQueries()))
+		for _, query := range request.GetQueries() {
+			data = append(data, &queryTemplateData{Query: query, Request: requestData})
+		}
+
+		return data, nil
+	case modePerTable:
+		data := []any{}
+		for _, schema := range request.GetCatalog().GetSchemas() {
+			for _, table := range schema.GetTables() {
+				data = append(data, &tableTemplateData{Table: table, Schema: schema, Request: requestData})
+			}
+		}
+
+		return data, nil
+	case modePerEnum:
+		data := []any{}
+		for _, schema := range request.GetCatalog().GetSchemas() {
+			for _, enum := range schema.GetEnums() {
+				data = append(data, &enumTemplateData{Enum: enum, Schema: schema, Request: requestData})
+			}
+		}
+
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+}
+
+// describeTemplateData returns a description of a fan-out root data object used in error messages, or an empty string
+// for the "single" mode.
+func describeTemplateData(data any) string {
+	switch data := data.(type) {
+	case *queryTemplateData:
+		return fmt.Sprintf("query %q", data.GetName())
+	case *queryFileTemplateData:
+		return fmt.Sprintf("query file %q", data.Filename)
+	case *tableTemplateData:
+		return fmt.Sprintf("table %q", data.Schema.GetName()+"."+data.GetRel().GetName())
+	case *enumTemplateData:
+		return fmt.Sprintf("enum %q", data.Schema.GetName()+"."+data.GetName())
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,79 @@
+package code
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// templateErrorLocation matches the location of the text/template parse and execution errors, formatted as
+// "template: <name>:<line>:" or "template: <name>:<line>:<column>:".
+var templateErrorLocation = regexp.MustCompile(`template: (.+?):(\d+):(?:(\d+):)?`)
+
+// snippetContextLines is the number of lines shown before and after the line of an error.
+const snippetContextLines = 2
+
+// describeTemplateError appends the template source lines around the location of a text/template error to the error,
+// with a caret pointing to the column when the error has one. sources maps each template name to its text.
+func describeTemplateError(err error, sources map[string]string) error {
+	match := templateErrorLocation.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+
+	source, ok := sources[match[1]]
+	if !ok {
+		return err
+	}
+
+	line, _ := strconv.Atoi(match[2])
+	column := -1
+	if match[3] != "" {
+		column, _ = strconv.Atoi(match[3])
+	}
+
+	snippet, ok := sourceSnippet(source, line, column)
+	if !ok {
+		return err
+	}
+
+	return fmt.Errorf("%w\n%s:%s", err, match[1], snippet)
+}
+
+// sourceSnippet returns the source lines around a 1-based line number, with a caret pointing to the 0-based byte
+// column when the column is not negative. It returns false when the line is not part of the source.
+func sourceSnippet(source string, line int, column int) (string, bool) {
+	lines := strings.Split(source, "\n")
+	if line < 1 || line > len(lines) {
+		return "", false
+	}
+
+	first := max(line-snippetContextLines, 1)
+	last := min(line+snippetContextLines, len(lines))
+	width := len(strconv.Itoa(last))
+
+	snippet := strings.Builder{}
+	for number := first; number <= last; number++ {
+		text := lines[number-1]
+		marker := " "
+		if number == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&snippet, "\n%s %*d | %s", marker, width, number, text)
+
+		if number == line && column >= 0 && column <= len(text) {
+			// Keep the tabs so that the caret is aligned with the column.
+			padding := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+
+				return ' '
+			}, text[:column])
+			fmt.Fprintf(&snippet, "\n  %*s | %s^", width, "", padding)
+		}
+	}
+
+	return snippet.String(), true
+}
@@ -0,0 +1,142 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestTemplateErrors(t *testing.T) {
+	testCases := map[string]struct {
+		request        *plugin.GenerateRequest
+		expectedErrMsg string
+	}{
+		"execution error": {
+			request: &plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor"}},
+				PluginOptions: []byte(`{
+					"filename": "test.file",
+					"template": "` + jsonString(`queries:
+{{- range .Queries }}
+  - name: {{ .Name }}
+    columns: {{ .Colums }}
+{{- end }}
+`) + `"
+				}`),
+			},
+			expectedErrMsg: `failed to execute the template, template: template:4:16: executing "template" at <.Colums>: ` +
+				`can't evaluate field Colums in type *plugin.Query
+template:
+  2 | {{- range .Queries }}
+  3 |   - name: {{ .Name }}
+> 4 |     columns: {{ .Colums }}
+    |                 ^
+  5 | {{- end }}
+  6 | `,
+		},
+		"parse error": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"filename": "test.file",
+					"template": "` + jsonString("line 1\n{{ if }}\nline 3") + `"
+				}`),
+			},
+			expectedErrMsg: `failed to parse the template, template: template:2: missing value for if
+template:
+  1 | line 1
+> 2 | {{ if }}
+  3 | line 3`,
+		},
+		"partial execution error": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"partials": {"header": "// header\n\t{{ .Invalid }}"},
+					"filename": "test.file",
+					"template": "{{ template \"header\" . }}"
+				}`),
+			},
+			expectedErrMsg: `failed to execute the template, template: header:2:4: executing "header" at <.Invalid>: ` +
+				`can't evaluate field Invalid in type *code.requestTemplateData
+header:
+  1 | // header
+> 2 | 	{{ .Invalid }}
+    | 	   ^`,
+		},
+		"partial parse error": {
+			request: &plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"partials": {"header": "{{ end }}"},
+					"filename": "test.file",
+					"template": ""
+				}`),
+			},
+			expectedErrMsg: `failed to parse the "header" partial template, template: header:1: unexpected {{end}}
+header:
+> 1 | {{ end }}`,
+		},
+		"fan-out query": {
+			request: &plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor"}, {Name: "ListAuthors", Cmd: ":many"}},
+				PluginOptions: []byte(`{
+					"mode": "per-query",
+					"filename": "{{ .Name }}.go",
+					"template": "{{ if .Cmd }}{{ .Invalid }}{{ end }}"
+				}`),
+			},
+			expectedErrMsg: `failed to execute the template for the query "ListAuthors", template: template:1:16:`,
+		},
+		"fan-out table filename": {
+			request: &plugin.GenerateRequest{
+				Catalog: &plugin.Catalog{
+					Schemas: []*plugin.Schema{
+						{Name: "public", Tables: []*plugin.Table{{Rel: &plugin.Identifier{Name: "authors"}}}},
+					},
+				},
+				PluginOptions: []byte(`{
+					"mode": "per-table",
+					"filename": "{{ .Name }}.go",
+					"template": ""
+				}`),
+			},
+			expectedErrMsg: `failed to execute the filename template for the table "public.authors", template: filename:1:3:`,
+		},
+		"fan-out enum": {
+			request: &plugin.GenerateRequest{
+				Catalog: &plugin.Catalog{
+					Schemas: []*plugin.Schema{
+						{Name: "public", Enums: []*plugin.Enum{{Name: "status"}}},
+					},
+				},
+				PluginOptions: []byte(`{
+					"mode": "per-enum",
+					"filename": "{{ .Name }}.go",
+					"template": "{{ .Invalid }}"
+				}`),
+			},
+			expectedErrMsg: `failed to execute the template for the enum "public.status"`,
+		},
+		"fan-out query file": {
+			request: &plugin.GenerateRequest{
+				Queries: []*plugin.Query{{Name: "GetAuthor", Filename: "authors.sql"}},
+				PluginOptions: []byte(`{
+					"group_by": "query_file",
+					"filename": "{{ .Filename }}.go",
+					"template": "{{ .Invalid }}"
+				}`),
+			},
+			expectedErrMsg: `failed to execute the template for the query file "authors.sql"`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			_, err := code.Generate(testCase.request)
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
@@ -1,14 +1,18 @@
 package code
 
 import (
+	"fmt"
+	"slices"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
 )
 
 type StringTransformer = func(string) string
 
-func getTemplateFunctions() template.FuncMap {
+func getTemplateFunctions(request *plugin.GenerateRequest, options *pluginOptions) template.FuncMap {
 	funcMap := sprig.FuncMap()
 
 	delete(funcMap, "osBase")
@@ -47,5 +51,81 @@ func getTemplateFunctions() template.FuncMap {
 	funcMap["ToCamel"] = camelcase
 	funcMap["ToLowerCamel"] = func(s string) string { return untitle(camelcase(s)) }
 
+	if options.Deterministic {
+		makeDeterministic(funcMap)
+	}
+
+	// Type mapping functions:
+	funcMap["GoType"] = getGoTypeFunction(request, options.Overrides)
+	funcMap["TsType"] = tsTypeMapper.templateFunction(request, options.Overrides)
+	funcMap["RustType"] = rustTypeMapper.templateFunction(request, options.Overrides)
+	funcMap["KotlinType"] = kotlinTypeMapper.templateFunction(request, options.Overrides)
+	funcMap["PythonType"] = pythonTypeMapper.templateFunction(request, options.Overrides)
+	funcMap["CSharpType"] = csharpTypeMapper.templateFunction(request, options.Overrides)
+
+	// Query text functions:
+	funcMap["RewritePlaceholders"] = getRewritePlaceholdersFunction(request)
+	funcMap["PlaceholderParams"] = getPlaceholderParamsFunction(request)
+
+	// Query parameter functions:
+	funcMap["SliceParams"] = sliceParams
+	funcMap["SliceMarker"] = sliceMarker
+	funcMap["SliceExpansion"] = sliceExpansion
+	funcMap["IsNarg"] = isNarg
+
 	return funcMap
 }
+
+// nonDeterministicFunctions are the sprig functions whose result depends on the current time, the time zone or a random
+// source, they are disabled by the `deterministic` option.
+var nonDeterministicFunctions = []string{
+	// Time functions:
+	"now", "ago", "date", "dateInZone", "date_in_zone", "dateModify", "date_modify", "mustDateModify",
+	"must_date_modify", "htmlDate", "htmlDateInZone", "toDate", "mustToDate", "unixEpoch", "durationRound",
+	// Random functions:
+	"randAlphaNum", "randAlpha", "randAscii", "randNumeric", "randInt", "randBytes", "shuffle", "uuidv4",
+	// Cryptographic generation functions:
+	"bcrypt", "htpasswd", "genPrivateKey", "buildCustomCert", "genCA", "genCAWithKey", "genSelfSignedCert",
+	"genSelfSignedCertWithKey", "genSignedCert", "genSignedCertWithKey", "encryptAES",
+}
+
+// makeDeterministic replaces the non deterministic functions with functions that fail with an explanation and sorts the
+// results of the `keys` and `values` functions that otherwise follow the random map iteration order.
+func makeDeterministic(funcMap template.FuncMap) {
+	for _, name := range nonDeterministicFunctions {
+		funcMap[name] = func(...any) (any, error) {
+			return nil, fmt.Errorf(
+				"the %q function is disabled by the sqlc config '%s.deterministic' field, its result changes "+
+					"between runs",
+				name, optionsPath,
+			)
+		}
+	}
+
+	funcMap["keys"] = func(dicts ...map[string]any) []string {
+		keys := []string{}
+		for _, dict := range dicts {
+			for key := range dict {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		return keys
+	}
+
+	funcMap["values"] = func(dict map[string]any) []any {
+		keys := make([]string, 0, len(dict))
+		for key := range dict {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		values := make([]any, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, dict[key])
+		}
+
+		return values
+	}
+}
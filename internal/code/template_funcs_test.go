@@ -99,7 +99,7 @@ func TestCodeGeneratorTemplateFuncs(t *testing.T) {
 			assert.NoError(t, err)
 
 			responseBuffer := &bytes.Buffer{}
-			err = code.GenerateFromReader(requestReader, responseBuffer)
+			err = code.GenerateFromReader(requestReader, responseBuffer, code.Config{})
 			assert.NoError(t, err)
 
 			response, err := responseFromReader(responseBuffer)
@@ -109,3 +109,63 @@ func TestCodeGeneratorTemplateFuncs(t *testing.T) {
 		})
 	}
 }
+
+func TestDeterministicTemplateFuncs(t *testing.T) {
+	testCases := map[string]struct {
+		template         string
+		expectedContents string
+		expectedErrMsg   string
+	}{
+		"now": {
+			template:       `{{ now | date "2006" }}`,
+			expectedErrMsg: `error calling now: the "now" function is disabled by the sqlc config 'sql[].codegen.options.deterministic' field`,
+		},
+		"uuidv4": {
+			template:       `{{ uuidv4 }}`,
+			expectedErrMsg: `error calling uuidv4: the "uuidv4" function is disabled`,
+		},
+		"randInt": {
+			template:       `{{ randInt 1 10 }}`,
+			expectedErrMsg: `error calling randInt: the "randInt" function is disabled`,
+		},
+		"genPrivateKey": {
+			template:       `{{ genPrivateKey "rsa" }}`,
+			expectedErrMsg: `error calling genPrivateKey: the "genPrivateKey" function is disabled`,
+		},
+		"sorted keys": {
+			template:         `{{ keys (dict "c" 1 "a" 2 "b" 3) (dict "d" 4) | join "," }}`,
+			expectedContents: `a,b,c,d`,
+		},
+		"sorted values": {
+			template:         `{{ values (dict "c" 1 "a" 2 "b" 3) | join "," }}`,
+			expectedContents: `2,3,1`,
+		},
+		"deterministic functions": {
+			template:         `{{ "foo" | sha256sum | trunc 8 }} {{ list "b" "a" | sortAlpha | join "," }}`,
+			expectedContents: `2c26b46b a,b`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			response, err := code.Generate(&plugin.GenerateRequest{
+				PluginOptions: []byte(`{
+					"deterministic": true,
+					"filename": "",
+					"template": "` + jsonString(testCase.template) + `"
+				}`),
+			})
+			if testCase.expectedErrMsg != "" {
+				assert.ErrorContains(t, err, testCase.expectedErrMsg)
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testCase.expectedContents, string(response.Files[0].Contents))
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/huandu/xstrings"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+const (
+	enginePostgreSQL = "postgresql"
+	engineMySQL      = "mysql"
+	engineSQLite     = "sqlite"
+)
+
+var engines = []string{enginePostgreSQL, engineMySQL, engineSQLite}
+
+// getEngine returns the engine passed to a type mapping template function or, if none was passed, the sqlc config
+// 'sql[].engine' field.
+func getEngine(request *plugin.GenerateRequest, args []string) (string, error) {
+	engine := request.GetSettings().GetEngine()
+	if len(args) > 0 && args[0] != "" {
+		engine = args[0]
+	}
+
+	switch engine {
+	case enginePostgreSQL, engineMySQL, engineSQLite:
+		return engine, nil
+	default:
+		return "", fmt.Errorf("invalid engine %q, must be one of: %q", engine, engines)
+	}
+}
+
+// getColumnTypeName returns the lower case column type name without the "pg_catalog" schema and without type
+// modifiers, for example "pg_catalog.varchar(255)" becomes "varchar".
+func getColumnTypeName(column *plugin.Column) string {
+	name := strings.ToLower(column.GetType().GetName())
+	name = strings.TrimPrefix(name, "pg_catalog.")
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+	}
+
+	return strings.TrimSpace(name)
+}
+
+// findEnum returns the catalog enum of the column type or nil if the column type is not an enum.
+func findEnum(request *plugin.GenerateRequest, column *plugin.Column) (*plugin.Schema, *plugin.Enum) {
+	if column.GetType() == nil {
+		return nil, nil
+	}
+
+	schema := findSchema(request, column.GetType())
+	for _, enum := range schema.GetEnums() {
+		if enum.GetName() == column.GetType().GetName() {
+			return schema, enum
+		}
+	}
+
+	return nil, nil
+}
+
+// getEnumTypeName returns the generated type name of an enum, enums outside the catalog default schema are prefixed
+// with the schema name.
+func getEnumTypeName(request *plugin.GenerateRequest, schema *plugin.Schema, enum *plugin.Enum) string {
+	if schema.GetName() != "" && schema.GetName() != request.GetCatalog().GetDefaultSchema() {
+		return xstrings.ToPascalCase(schema.GetName() + "_" + enum.GetName())
+	}
+
+	return xstrings.ToPascalCase(enum.GetName())
+}
+
+// getArrayDims returns the number of array dimensions of the column, sqlc.slice() parameters have one dimension.
+func getArrayDims(column *plugin.Column) int {
+	if column.GetIsArray() {
+		return max(int(column.GetArrayDims()), 1)
+	}
+
+	if column.GetIsSqlcSlice() {
+		return 1
+	}
+
+	return 0
+}
+
+// sqlType is an engine independent database type category, each language type mapper maps the categories to its own
+// types.
+type sqlType string
+
+const (
+	sqlTypeInt8        sqlType = "int8"
+	sqlTypeInt16       sqlType = "int16"
+	sqlTypeInt32       sqlType = "int32"
+	sqlTypeInt64       sqlType = "int64"
+	sqlTypeUint8       sqlType = "uint8"
+	sqlTypeUint16      sqlType = "uint16"
+	sqlTypeUint32      sqlType = "uint32"
+	sqlTypeUint64      sqlType = "uint64"
+	sqlTypeFloat32     sqlType = "float32"
+	sqlTypeFloat64     sqlType = "float64"
+	sqlTypeDecimal     sqlType = "decimal"
+	sqlTypeBool        sqlType = "bool"
+	sqlTypeString      sqlType = "string"
+	sqlTypeUUID        sqlType = "uuid"
+	sqlTypeDate        sqlType = "date"
+	sqlTypeTime        sqlType = "time"
+	sqlTypeTimestamp   sqlType = "timestamp"
+	sqlTypeTimestampTZ sqlType = "timestamptz"
+	sqlTypeInterval    sqlType = "interval"
+	sqlTypeJSON        sqlType = "json"
+	sqlTypeBytes       sqlType = "bytes"
+	sqlTypeInet        sqlType = "inet"
+	sqlTypeCIDR        sqlType = "cidr"
+	sqlTypeMacAddr     sqlType = "macaddr"
+	// sqlTypeAny is the category of the types without a specific type, like "void", mapped to the language "any" type.
+	sqlTypeAny sqlType = "any"
+)
+
+// unsignedSQLTypes maps the integer categories to their unsigned variants.
+var unsignedSQLTypes = map[sqlType]sqlType{
+	sqlTypeInt8:  sqlTypeUint8,
+	sqlTypeInt16: sqlTypeUint16,
+	sqlTypeInt32: sqlTypeUint32,
+	sqlTypeInt64: sqlTypeUint64,
+}
+
+var sqlTypes = map[string]map[string]sqlType{
+	enginePostgreSQL: {
+		"smallserial":                 sqlTypeInt16,
+		"serial2":                     sqlTypeInt16,
+		"smallint":                    sqlTypeInt16,
+		"int2":                        sqlTypeInt16,
+		"serial":                      sqlTypeInt32,
+		"serial4":                     sqlTypeInt32,
+		"int":                         sqlTypeInt32,
+		"int4":                        sqlTypeInt32,
+		"integer":                     sqlTypeInt32,
+		"bigserial":                   sqlTypeInt64,
+		"serial8":                     sqlTypeInt64,
+		"bigint":                      sqlTypeInt64,
+		"int8":                        sqlTypeInt64,
+		"real":                        sqlTypeFloat32,
+		"float4":                      sqlTypeFloat32,
+		"float":                       sqlTypeFloat64,
+		"float8":                      sqlTypeFloat64,
+		"double precision":            sqlTypeFloat64,
+		"numeric":                     sqlTypeDecimal,
+		"decimal":                     sqlTypeDecimal,
+		"money":                       sqlTypeString,
+		"bool":                        sqlTypeBool,
+		"boolean":                     sqlTypeBool,
+		"text":                        sqlTypeString,
+		"varchar":                     sqlTypeString,
+		"character varying":           sqlTypeString,
+		"char":                        sqlTypeString,
+		"bpchar":                      sqlTypeString,
+		"character":                   sqlTypeString,
+		"citext":                      sqlTypeString,
+		"name":                        sqlTypeString,
+		"inet":                        sqlTypeInet,
+		"cidr":                        sqlTypeCIDR,
+		"macaddr":                     sqlTypeMacAddr,
+		"timetz":                      sqlTypeString,
+		"time with time zone":         sqlTypeString,
+		"uuid":                        sqlTypeUUID,
+		"date":                        sqlTypeDate,
+		"time":                        sqlTypeTime,
+		"time without time zone":      sqlTypeTime,
+		"timestamp":                   sqlTypeTimestamp,
+		"timestamp without time zone": sqlTypeTimestamp,
+		"timestamptz":                 sqlTypeTimestampTZ,
+		"timestamp with time zone":    sqlTypeTimestampTZ,
+		"interval":                    sqlTypeInterval,
+		"json":                        sqlTypeJSON,
+		"jsonb":                       sqlTypeJSON,
+		"bytea":                       sqlTypeBytes,
+		"blob":                        sqlTypeBytes,
+		"void":                        sqlTypeAny,
+		"any":                         sqlTypeAny,
+	},
+	engineMySQL: {
+		"tinyint":          sqlTypeInt8,
+		"smallint":         sqlTypeInt16,
+		"year":             sqlTypeInt16,
+		"mediumint":        sqlTypeInt32,
+		"int":              sqlTypeInt32,
+		"integer":          sqlTypeInt32,
+		"bigint":           sqlTypeInt64,
+		"float":            sqlTypeFloat64,
+		"double":           sqlTypeFloat64,
+		"double precision": sqlTypeFloat64,
+		"real":             sqlTypeFloat64,
+		"decimal":          sqlTypeDecimal,
+		"dec":              sqlTypeDecimal,
+		"fixed":            sqlTypeDecimal,
+		"bool":             sqlTypeBool,
+		"boolean":          sqlTypeBool,
+		"char":             sqlTypeString,
+		"varchar":          sqlTypeString,
+		"text":             sqlTypeString,
+		"tinytext":         sqlTypeString,
+		"mediumtext":       sqlTypeString,
+		"longtext":         sqlTypeString,
+		"enum":             sqlTypeString,
+		"set":              sqlTypeString,
+		"date":             sqlTypeDate,
+		"time":             sqlTypeTime,
+		"datetime":         sqlTypeTimestamp,
+		"timestamp":        sqlTypeTimestamp,
+		"json":             sqlTypeJSON,
+		"blob":             sqlTypeBytes,
+		"tinyblob":         sqlTypeBytes,
+		"mediumblob":       sqlTypeBytes,
+		"longblob":         sqlTypeBytes,
+		"binary":           sqlTypeBytes,
+		"varbinary":        sqlTypeBytes,
+		"any":              sqlTypeAny,
+	},
+	engineSQLite: {
+		"int":              sqlTypeInt64,
+		"integer":          sqlTypeInt64,
+		"tinyint":          sqlTypeInt64,
+		"smallint":         sqlTypeInt64,
+		"mediumint":        sqlTypeInt64,
+		"bigint":           sqlTypeInt64,
+		"unsignedbigint":   sqlTypeInt64,
+		"int2":             sqlTypeInt64,
+		"int8":             sqlTypeInt64,
+		"real":             sqlTypeFloat64,
+		"double":           sqlTypeFloat64,
+		"double precision": sqlTypeFloat64,
+		"float":            sqlTypeFloat64,
+		"numeric":          sqlTypeFloat64,
+		"decimal":          sqlTypeFloat64,
+		"bool":             sqlTypeBool,
+		"boolean":          sqlTypeBool,
+		"text":             sqlTypeString,
+		"varchar":          sqlTypeString,
+		"char":             sqlTypeString,
+		"nchar":            sqlTypeString,
+		"nvarchar":         sqlTypeString,
+		"clob":             sqlTypeString,
+		"date":             sqlTypeDate,
+		"datetime":         sqlTypeTimestamp,
+		"timestamp":        sqlTypeTimestamp,
+		"json":             sqlTypeJSON,
+		"blob":             sqlTypeBytes,
+		"any":              sqlTypeAny,
+	},
+}
+
+// getSQLType returns the type category of a column for the given engine.
+func getSQLType(engine string, column *plugin.Column) (sqlType, bool) {
+	name := getColumnTypeName(column)
+
+	// MySQL does not have a boolean type, "tinyint(1)" is used instead.
+	if engine == engineMySQL && name == "tinyint" && column.GetLength() == 1 {
+		return sqlTypeBool, true
+	}
+
+	typ, ok := sqlTypes[engine][name]
+	if ok && column.GetUnsigned() {
+		if unsigned, ok := unsignedSQLTypes[typ]; ok {
+			return unsigned, true
+		}
+	}
+
+	return typ, ok
+}
+
+// typeMapper maps columns to the types of a programming language.
+type typeMapper struct {
+	// function is the template function name, used in error messages.
+	function string
+	types    map[sqlType]string
+	// anyType is the type of the columns without a known type category.
+	anyType  string
+	nullable func(typ string) string
+	array    func(typ string) string
+	// override returns the type override of the template function.
+	override func(override *typeOverride) *string
+}
+
+// typeName returns the language type name of a column.
+// Array elements are never nullable and sqlc.slice() parameters are never null.
+func (m *typeMapper) typeName(request *plugin.GenerateRequest, engine string, column *plugin.Column) string {
+	name := m.anyType
+	if schema, enum := findEnum(request, column); enum != nil {
+		name = getEnumTypeName(request, schema, enum)
+	} else if typ, ok := getSQLType(engine, column); ok && typ != sqlTypeAny {
+		name = m.types[typ]
+	}
+
+	return m.wrap(column, name)
+}
+
+// wrap returns the language type name of a column from the name of its element type.
+func (m *typeMapper) wrap(column *plugin.Column, name string) string {
+	for range getArrayDims(column) {
+		name = m.array(name)
+	}
+
+	if !column.GetNotNull() && !column.GetIsSqlcSlice() {
+		name = m.nullable(name)
+	}
+
+	return name
+}
+
+// templateFunction returns the `<function> column [engine]` template function of the type mapper.
+// The engine defaults to the sqlc config 'sql[].engine' field and the overrides take precedence over the type mapper.
+func (m *typeMapper) templateFunction(
+	request *plugin.GenerateRequest,
+	overrides []typeOverride,
+) func(*plugin.Column, ...string) (string, error) {
+	return func(column *plugin.Column, args ...string) (string, error) {
+		if column == nil {
+			return "", fmt.Errorf("%s: the column is nil", m.function)
+		}
+
+		if len(args) > 1 {
+			return "", fmt.Errorf("%s: expected at most 1 argument after the column, got %d", m.function, len(args))
+		}
+
+		engine, err := getEngine(request, args)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", m.function, err)
+		}
+
+		if typ, ok := findTypeOverride(request, overrides, engine, column, m.override); ok {
+			if getArrayDims(column) == 0 {
+				return typ, nil
+			}
+
+			return m.wrap(column, typ), nil
+		}
+
+		return m.typeName(request, engine, column), nil
+	}
+}
@@ -0,0 +1,35 @@
+package code
+
+var csharpTypeMapper = &typeMapper{
+	function: "CSharpType",
+	types: map[sqlType]string{
+		sqlTypeInt8:        "sbyte",
+		sqlTypeInt16:       "short",
+		sqlTypeInt32:       "int",
+		sqlTypeInt64:       "long",
+		sqlTypeUint8:       "byte",
+		sqlTypeUint16:      "ushort",
+		sqlTypeUint32:      "uint",
+		sqlTypeUint64:      "ulong",
+		sqlTypeFloat32:     "float",
+		sqlTypeFloat64:     "double",
+		sqlTypeDecimal:     "decimal",
+		sqlTypeBool:        "bool",
+		sqlTypeString:      "string",
+		sqlTypeUUID:        "Guid",
+		sqlTypeDate:        "DateOnly",
+		sqlTypeTime:        "TimeOnly",
+		sqlTypeTimestamp:   "DateTime",
+		sqlTypeTimestampTZ: "DateTimeOffset",
+		sqlTypeInterval:    "TimeSpan",
+		sqlTypeJSON:        "JsonElement",
+		sqlTypeBytes:       "byte[]",
+		sqlTypeInet:        "string",
+		sqlTypeCIDR:        "string",
+		sqlTypeMacAddr:     "string",
+	},
+	anyType:  "object",
+	nullable: func(typ string) string { return typ + "?" },
+	array:    func(typ string) string { return typ + "[]" },
+	override: func(override *typeOverride) *string { return override.CSharpType },
+}
@@ -0,0 +1,66 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestCSharpType(t *testing.T) {
+	testCases := map[string]struct {
+		engine   string
+		column   *plugin.Column
+		args     string
+		expected string
+	}{
+		"postgresql not null bigint": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "int8"}},
+			expected: "long",
+		},
+		"postgresql null bigint": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "bigint"}},
+			expected: "long?",
+		},
+		"postgresql null array": {
+			engine:   "postgresql",
+			column:   &plugin.Column{IsArray: true, ArrayDims: 1, Type: &plugin.Identifier{Name: "text"}},
+			expected: "string[]?",
+		},
+		"postgresql uuid": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "uuid"}},
+			expected: "Guid",
+		},
+		"postgresql unknown type": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "tsvector"}},
+			expected: "object?",
+		},
+		"mysql unsigned bigint": {
+			engine:   "mysql",
+			column:   &plugin.Column{NotNull: true, Unsigned: true, Type: &plugin.Identifier{Name: "bigint"}},
+			expected: "ulong",
+		},
+		"sqlite real": {
+			engine:   "sqlite",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "REAL"}},
+			expected: "double?",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			actual, err := generateType(
+				t, createTypeTestGenerateRequest(testCase.engine, testCase.column, "CSharpType", testCase.args),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
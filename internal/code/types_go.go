@@ -0,0 +1,166 @@
+package code
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+const (
+	// goTypeStyleDatabaseSQL maps nullable columns to the `database/sql` `sql.Null*` types.
+	goTypeStyleDatabaseSQL = "database/sql"
+	// goTypeStylePgx maps columns to the `github.com/jackc/pgx/v5/pgtype` types, like the sqlc-gen-go pgx/v5 driver.
+	goTypeStylePgx = "pgx/v5"
+	// goTypeStylePointer maps nullable columns to pointers of the not null types.
+	goTypeStylePointer = "pointer"
+)
+
+var goTypeStyles = []string{goTypeStyleDatabaseSQL, goTypeStylePgx, goTypeStylePointer}
+
+// goType is the Go type of a database type category.
+type goType struct {
+	notNull string
+	// null is the `database/sql` nullable type, types that are already nullable (like slices) leave it empty.
+	null string
+	// pqNull is the PostgreSQL `database/sql` nullable type, if empty null is used.
+	pqNull string
+	// pgx is the pgx/v5 not null type, if empty notNull is used.
+	pgx string
+	// pgxNull is the pgx/v5 nullable type, if empty the pgx/v5 not null type or null is used.
+	pgxNull string
+}
+
+// goTypes maps the database type categories to Go types, following the sqlc-gen-go type mapping.
+// The PostgreSQL `database/sql` network and nullable JSON types are the `github.com/sqlc-dev/pqtype` types and, as the
+// `database/sql` package does not have a `sql.NullInt8` type, the nullable MySQL 8 and 16 bits integers are
+// `sql.NullInt16` and the nullable unsigned integers use the nullable type of the signed integer of the same size.
+var goTypes = map[sqlType]goType{
+	sqlTypeInt8:    {notNull: "int8", null: "sql.NullInt16"},
+	sqlTypeInt16:   {notNull: "int16", null: "sql.NullInt16", pgxNull: "pgtype.Int2"},
+	sqlTypeInt32:   {notNull: "int32", null: "sql.NullInt32", pgxNull: "pgtype.Int4"},
+	sqlTypeInt64:   {notNull: "int64", null: "sql.NullInt64", pgxNull: "pgtype.Int8"},
+	sqlTypeUint8:   {notNull: "uint8", null: "sql.NullInt16"},
+	sqlTypeUint16:  {notNull: "uint16", null: "sql.NullInt16"},
+	sqlTypeUint32:  {notNull: "uint32", null: "sql.NullInt32"},
+	sqlTypeUint64:  {notNull: "uint64", null: "sql.NullInt64"},
+	sqlTypeFloat32: {notNull: "float32", null: "sql.NullFloat64", pgxNull: "pgtype.Float4"},
+	sqlTypeFloat64: {notNull: "float64", null: "sql.NullFloat64", pgxNull: "pgtype.Float8"},
+	sqlTypeDecimal: {notNull: "string", null: "sql.NullString", pgx: "pgtype.Numeric", pgxNull: "pgtype.Numeric"},
+	sqlTypeBool:    {notNull: "bool", null: "sql.NullBool", pgxNull: "pgtype.Bool"},
+	sqlTypeString:  {notNull: "string", null: "sql.NullString", pgxNull: "pgtype.Text"},
+	sqlTypeUUID:    {notNull: "uuid.UUID", null: "uuid.NullUUID", pgx: "pgtype.UUID", pgxNull: "pgtype.UUID"},
+	sqlTypeDate:    {notNull: "time.Time", null: "sql.NullTime", pgx: "pgtype.Date", pgxNull: "pgtype.Date"},
+	sqlTypeTime:    {notNull: "time.Time", null: "sql.NullTime", pgx: "pgtype.Time", pgxNull: "pgtype.Time"},
+	sqlTypeTimestamp: {
+		notNull: "time.Time", null: "sql.NullTime", pgx: "pgtype.Timestamp", pgxNull: "pgtype.Timestamp",
+	},
+	sqlTypeTimestampTZ: {
+		notNull: "time.Time", null: "sql.NullTime", pgx: "pgtype.Timestamptz", pgxNull: "pgtype.Timestamptz",
+	},
+	sqlTypeInterval: {notNull: "int64", null: "sql.NullInt64", pgx: "pgtype.Interval", pgxNull: "pgtype.Interval"},
+	sqlTypeJSON:     {notNull: "json.RawMessage", pqNull: "pqtype.NullRawMessage", pgx: "[]byte"},
+	sqlTypeBytes:    {notNull: "[]byte"},
+	sqlTypeInet:     {notNull: "pqtype.Inet", pgx: "netip.Addr", pgxNull: "*netip.Addr"},
+	sqlTypeCIDR:     {notNull: "pqtype.CIDR", pgx: "netip.Prefix", pgxNull: "*netip.Prefix"},
+	sqlTypeMacAddr:  {notNull: "pqtype.Macaddr", pgx: "net.HardwareAddr", pgxNull: "net.HardwareAddr"},
+	sqlTypeAny:      {notNull: "any"},
+}
+
+func getGoTypeOverride(override *typeOverride) *string { return override.GoType }
+
+// goTypeName returns the Go type name of a column for the given style.
+// Array element types are always mapped as not null.
+func goTypeName(request *plugin.GenerateRequest, engine string, style string, column *plugin.Column) string {
+	arrayDims := getArrayDims(column)
+	notNull := column.GetNotNull() || arrayDims > 0
+	prefix := strings.Repeat("[]", arrayDims)
+
+	if schema, enum := findEnum(request, column); enum != nil {
+		name := getEnumTypeName(request, schema, enum)
+		switch {
+		case notNull:
+			return prefix + name
+		case style == goTypeStylePointer:
+			return "*" + name
+		default:
+			return "Null" + name
+		}
+	}
+
+	typ, ok := getSQLType(engine, column)
+	if !ok {
+		return prefix + "any"
+	}
+
+	goType := goTypes[typ]
+
+	switch style {
+	case goTypeStylePgx:
+		if notNull {
+			return prefix + cmp.Or(goType.pgx, goType.notNull)
+		}
+
+		return cmp.Or(goType.pgxNull, goType.pgx, goType.null, goType.notNull)
+	case goTypeStylePointer:
+		if notNull || goType.null == "" {
+			return prefix + goType.notNull
+		}
+
+		return "*" + goType.notNull
+	default:
+		if notNull {
+			return prefix + goType.notNull
+		}
+
+		if engine == enginePostgreSQL && goType.pqNull != "" {
+			return goType.pqNull
+		}
+
+		return cmp.Or(goType.null, goType.notNull)
+	}
+}
+
+// getGoTypeFunction returns the `GoType column [engine] [style]` template function.
+// The engine defaults to the sqlc config 'sql[].engine' field and the style to "database/sql".
+func getGoTypeFunction(
+	request *plugin.GenerateRequest,
+	overrides []typeOverride,
+) func(*plugin.Column, ...string) (string, error) {
+	return func(column *plugin.Column, args ...string) (string, error) {
+		if column == nil {
+			return "", fmt.Errorf("GoType: the column is nil")
+		}
+
+		if len(args) > 2 {
+			return "", fmt.Errorf("GoType: expected at most 2 arguments after the column, got %d", len(args))
+		}
+
+		engine, err := getEngine(request, args)
+		if err != nil {
+			return "", fmt.Errorf("GoType: %w", err)
+		}
+
+		style := goTypeStyleDatabaseSQL
+		if len(args) > 1 && args[1] != "" {
+			style = args[1]
+		}
+
+		switch style {
+		case goTypeStyleDatabaseSQL, goTypeStylePointer:
+		case goTypeStylePgx:
+			if engine != enginePostgreSQL {
+				return "", fmt.Errorf("GoType: the %q style is only supported by the %q engine", style, enginePostgreSQL)
+			}
+		default:
+			return "", fmt.Errorf("GoType: invalid style %q, must be one of: %q", style, goTypeStyles)
+		}
+
+		if goType, ok := findTypeOverride(request, overrides, engine, column, getGoTypeOverride); ok {
+			return strings.Repeat("[]", getArrayDims(column)) + goType, nil
+		}
+
+		return goTypeName(request, engine, style, column), nil
+	}
+}
@@ -0,0 +1,253 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestGoType(t *testing.T) {
+	testCases := map[string]struct {
+		engine   string
+		column   *plugin.Column
+		args     string
+		expected string
+	}{
+		"postgresql not null": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "bigserial"}},
+			expected: "int64",
+		},
+		"postgresql null": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "text"}},
+			expected: "sql.NullString",
+		},
+		"postgresql pg_catalog type": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Schema: "pg_catalog", Name: "pg_catalog.int4"}},
+			expected: "int32",
+		},
+		"postgresql type modifiers": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "varchar(255)"}},
+			expected: "string",
+		},
+		"postgresql array": {
+			engine:   "postgresql",
+			column:   &plugin.Column{IsArray: true, ArrayDims: 2, Type: &plugin.Identifier{Name: "int8"}},
+			expected: "[][]int64",
+		},
+		"postgresql sqlc.slice": {
+			engine:   "postgresql",
+			column:   &plugin.Column{IsSqlcSlice: true, Type: &plugin.Identifier{Name: "uuid"}},
+			expected: "[]uuid.UUID",
+		},
+		"postgresql unknown type": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "tsvector"}},
+			expected: "any",
+		},
+		"postgresql enum": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "book_type"}},
+			expected: "BookType",
+		},
+		"postgresql null enum": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "book_type"}},
+			expected: "NullBookType",
+		},
+		"postgresql enum outside the default schema": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Schema: "audit", Name: "action"}},
+			expected: "AuditAction",
+		},
+		"postgresql pgx/v5 not null": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "timestamptz"}},
+			args:     `"" "pgx/v5"`,
+			expected: "pgtype.Timestamptz",
+		},
+		"postgresql pgx/v5 null": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "int4"}},
+			args:     `"" "pgx/v5"`,
+			expected: "pgtype.Int4",
+		},
+		"postgresql pgx/v5 json": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "jsonb"}},
+			args:     `"" "pgx/v5"`,
+			expected: "[]byte",
+		},
+		"postgresql pointer null": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "bool"}},
+			args:     `"" "pointer"`,
+			expected: "*bool",
+		},
+		"postgresql pointer null bytes": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "bytea"}},
+			args:     `"" "pointer"`,
+			expected: "[]byte",
+		},
+		"postgresql pointer null enum": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "book_type"}},
+			args:     `"" "pointer"`,
+			expected: "*BookType",
+		},
+		"postgresql pgx/v5 inet": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "inet"}},
+			args:     `"" "pgx/v5"`,
+			expected: "*netip.Addr",
+		},
+		"postgresql inet": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "inet"}},
+			expected: "pqtype.Inet",
+		},
+		"postgresql cidr": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "cidr"}},
+			expected: "pqtype.CIDR",
+		},
+		"postgresql macaddr": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "macaddr"}},
+			expected: "pqtype.Macaddr",
+		},
+		"postgresql null json": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "jsonb"}},
+			expected: "pqtype.NullRawMessage",
+		},
+		"postgresql pointer null json": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "json"}},
+			args:     `"" "pointer"`,
+			expected: "json.RawMessage",
+		},
+		"postgresql blob": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "blob"}},
+			expected: "[]byte",
+		},
+		"postgresql void": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "void"}},
+			expected: "any",
+		},
+		"engine argument": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "int"}},
+			args:     `"sqlite"`,
+			expected: "int64",
+		},
+		"mysql null": {
+			engine:   "mysql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "datetime"}},
+			expected: "sql.NullTime",
+		},
+		"mysql unsigned": {
+			engine:   "mysql",
+			column:   &plugin.Column{NotNull: true, Unsigned: true, Type: &plugin.Identifier{Name: "bigint"}},
+			expected: "uint64",
+		},
+		"mysql null smallint": {
+			engine:   "mysql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "smallint"}},
+			expected: "sql.NullInt16",
+		},
+		"mysql null tinyint": {
+			engine:   "mysql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "tinyint"}},
+			expected: "sql.NullInt16",
+		},
+		"mysql null year": {
+			engine:   "mysql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "year"}},
+			expected: "sql.NullInt16",
+		},
+		"mysql unsigned null int": {
+			engine:   "mysql",
+			column:   &plugin.Column{Unsigned: true, Type: &plugin.Identifier{Name: "int"}},
+			expected: "sql.NullInt32",
+		},
+		"mysql null json": {
+			engine:   "mysql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "json"}},
+			expected: "json.RawMessage",
+		},
+		"mysql tinyint(1)": {
+			engine:   "mysql",
+			column:   &plugin.Column{Length: 1, Type: &plugin.Identifier{Name: "tinyint"}},
+			expected: "sql.NullBool",
+		},
+		"sqlite not null": {
+			engine:   "sqlite",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "INTEGER"}},
+			expected: "int64",
+		},
+		"sqlite null": {
+			engine:   "sqlite",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "REAL"}},
+			expected: "sql.NullFloat64",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			actual, err := generateType(
+				t, createTypeTestGenerateRequest(testCase.engine, testCase.column, "GoType", testCase.args),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestGoTypeFailure(t *testing.T) {
+	column := &plugin.Column{Type: &plugin.Identifier{Name: "text"}}
+	testCases := map[string]struct {
+		engine         string
+		args           string
+		expectedErrMsg string
+	}{
+		"invalid engine": {
+			engine:         "oracle",
+			expectedErrMsg: "GoType: invalid engine \"oracle\"",
+		},
+		"invalid style": {
+			engine:         "postgresql",
+			args:           `"" "gorm"`,
+			expectedErrMsg: "GoType: invalid style \"gorm\"",
+		},
+		"pgx/v5 style on mysql": {
+			engine:         "mysql",
+			args:           `"" "pgx/v5"`,
+			expectedErrMsg: "GoType: the \"pgx/v5\" style is only supported by the \"postgresql\" engine",
+		},
+		"too many arguments": {
+			engine:         "postgresql",
+			args:           `"" "pointer" "extra"`,
+			expectedErrMsg: "GoType: expected at most 2 arguments after the column, got 3",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			_, err := generateType(t, createTypeTestGenerateRequest(testCase.engine, column, "GoType", testCase.args))
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package code
+
+var kotlinTypeMapper = &typeMapper{
+	function: "KotlinType",
+	types: map[sqlType]string{
+		sqlTypeInt8:        "Byte",
+		sqlTypeInt16:       "Short",
+		sqlTypeInt32:       "Int",
+		sqlTypeInt64:       "Long",
+		sqlTypeUint8:       "UByte",
+		sqlTypeUint16:      "UShort",
+		sqlTypeUint32:      "UInt",
+		sqlTypeUint64:      "ULong",
+		sqlTypeFloat32:     "Float",
+		sqlTypeFloat64:     "Double",
+		sqlTypeDecimal:     "java.math.BigDecimal",
+		sqlTypeBool:        "Boolean",
+		sqlTypeString:      "String",
+		sqlTypeUUID:        "java.util.UUID",
+		sqlTypeDate:        "java.time.LocalDate",
+		sqlTypeTime:        "java.time.LocalTime",
+		sqlTypeTimestamp:   "java.time.LocalDateTime",
+		sqlTypeTimestampTZ: "java.time.OffsetDateTime",
+		sqlTypeInterval:    "String",
+		sqlTypeJSON:        "String",
+		sqlTypeBytes:       "ByteArray",
+		sqlTypeInet:        "String",
+		sqlTypeCIDR:        "String",
+		sqlTypeMacAddr:     "String",
+	},
+	anyType:  "Any",
+	nullable: func(typ string) string { return typ + "?" },
+	array:    func(typ string) string { return "List<" + typ + ">" },
+	override: func(override *typeOverride) *string { return override.KotlinType },
+}
@@ -0,0 +1,66 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestKotlinType(t *testing.T) {
+	testCases := map[string]struct {
+		engine   string
+		column   *plugin.Column
+		args     string
+		expected string
+	}{
+		"postgresql not null bigint": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "int8"}},
+			expected: "Long",
+		},
+		"postgresql null bigint": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "bigint"}},
+			expected: "Long?",
+		},
+		"postgresql array": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, IsArray: true, ArrayDims: 1, Type: &plugin.Identifier{Name: "uuid"}},
+			expected: "List<java.util.UUID>",
+		},
+		"postgresql numeric": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "numeric"}},
+			expected: "java.math.BigDecimal?",
+		},
+		"postgresql unknown type": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "tsvector"}},
+			expected: "Any",
+		},
+		"mysql tinyint(1)": {
+			engine:   "mysql",
+			column:   &plugin.Column{Length: 1, Type: &plugin.Identifier{Name: "tinyint"}},
+			expected: "Boolean?",
+		},
+		"sqlite datetime": {
+			engine:   "sqlite",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "DATETIME"}},
+			expected: "java.time.LocalDateTime",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			actual, err := generateType(
+				t, createTypeTestGenerateRequest(testCase.engine, testCase.column, "KotlinType", testCase.args),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
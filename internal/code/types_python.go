@@ -0,0 +1,35 @@
+package code
+
+var pythonTypeMapper = &typeMapper{
+	function: "PythonType",
+	types: map[sqlType]string{
+		sqlTypeInt8:        "int",
+		sqlTypeInt16:       "int",
+		sqlTypeInt32:       "int",
+		sqlTypeInt64:       "int",
+		sqlTypeUint8:       "int",
+		sqlTypeUint16:      "int",
+		sqlTypeUint32:      "int",
+		sqlTypeUint64:      "int",
+		sqlTypeFloat32:     "float",
+		sqlTypeFloat64:     "float",
+		sqlTypeDecimal:     "decimal.Decimal",
+		sqlTypeBool:        "bool",
+		sqlTypeString:      "str",
+		sqlTypeUUID:        "uuid.UUID",
+		sqlTypeDate:        "datetime.date",
+		sqlTypeTime:        "datetime.time",
+		sqlTypeTimestamp:   "datetime.datetime",
+		sqlTypeTimestampTZ: "datetime.datetime",
+		sqlTypeInterval:    "datetime.timedelta",
+		sqlTypeJSON:        "Any",
+		sqlTypeBytes:       "bytes",
+		sqlTypeInet:        "str",
+		sqlTypeCIDR:        "str",
+		sqlTypeMacAddr:     "str",
+	},
+	anyType:  "Any",
+	nullable: func(typ string) string { return "Optional[" + typ + "]" },
+	array:    func(typ string) string { return "List[" + typ + "]" },
+	override: func(override *typeOverride) *string { return override.PythonType },
+}
@@ -0,0 +1,66 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestPythonType(t *testing.T) {
+	testCases := map[string]struct {
+		engine   string
+		column   *plugin.Column
+		args     string
+		expected string
+	}{
+		"postgresql not null bigint": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "int8"}},
+			expected: "int",
+		},
+		"postgresql null int": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "int4"}},
+			expected: "Optional[int]",
+		},
+		"postgresql array": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, IsArray: true, ArrayDims: 2, Type: &plugin.Identifier{Name: "float8"}},
+			expected: "List[List[float]]",
+		},
+		"postgresql interval": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "interval"}},
+			expected: "datetime.timedelta",
+		},
+		"postgresql jsonb": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "jsonb"}},
+			expected: "Optional[Any]",
+		},
+		"mysql decimal": {
+			engine:   "mysql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "decimal"}},
+			expected: "decimal.Decimal",
+		},
+		"sqlite text": {
+			engine:   "sqlite",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "TEXT"}},
+			expected: "Optional[str]",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			actual, err := generateType(
+				t, createTypeTestGenerateRequest(testCase.engine, testCase.column, "PythonType", testCase.args),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package code
+
+var rustTypeMapper = &typeMapper{
+	function: "RustType",
+	types: map[sqlType]string{
+		sqlTypeInt8:        "i8",
+		sqlTypeInt16:       "i16",
+		sqlTypeInt32:       "i32",
+		sqlTypeInt64:       "i64",
+		sqlTypeUint8:       "u8",
+		sqlTypeUint16:      "u16",
+		sqlTypeUint32:      "u32",
+		sqlTypeUint64:      "u64",
+		sqlTypeFloat32:     "f32",
+		sqlTypeFloat64:     "f64",
+		sqlTypeDecimal:     "rust_decimal::Decimal",
+		sqlTypeBool:        "bool",
+		sqlTypeString:      "String",
+		sqlTypeUUID:        "uuid::Uuid",
+		sqlTypeDate:        "chrono::NaiveDate",
+		sqlTypeTime:        "chrono::NaiveTime",
+		sqlTypeTimestamp:   "chrono::NaiveDateTime",
+		sqlTypeTimestampTZ: "chrono::DateTime<chrono::Utc>",
+		sqlTypeInterval:    "String",
+		sqlTypeJSON:        "serde_json::Value",
+		sqlTypeBytes:       "Vec<u8>",
+		sqlTypeInet:        "String",
+		sqlTypeCIDR:        "String",
+		sqlTypeMacAddr:     "String",
+	},
+	anyType:  "String",
+	nullable: func(typ string) string { return "Option<" + typ + ">" },
+	array:    func(typ string) string { return "Vec<" + typ + ">" },
+	override: func(override *typeOverride) *string { return override.RustType },
+}
@@ -0,0 +1,72 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestRustType(t *testing.T) {
+	testCases := map[string]struct {
+		engine   string
+		column   *plugin.Column
+		args     string
+		expected string
+	}{
+		"postgresql not null bigint": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "int8"}},
+			expected: "i64",
+		},
+		"postgresql null bigint": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "bigint"}},
+			expected: "Option<i64>",
+		},
+		"postgresql null array": {
+			engine:   "postgresql",
+			column:   &plugin.Column{IsArray: true, ArrayDims: 1, Type: &plugin.Identifier{Name: "text"}},
+			expected: "Option<Vec<String>>",
+		},
+		"postgresql timestamptz": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "timestamptz"}},
+			expected: "chrono::DateTime<chrono::Utc>",
+		},
+		"postgresql enum": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "book_type"}},
+			expected: "BookType",
+		},
+		"mysql unsigned int": {
+			engine:   "mysql",
+			column:   &plugin.Column{NotNull: true, Unsigned: true, Type: &plugin.Identifier{Name: "int"}},
+			expected: "u32",
+		},
+		"sqlite blob": {
+			engine:   "sqlite",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "BLOB"}},
+			expected: "Option<Vec<u8>>",
+		},
+		"engine argument": {
+			engine:   "sqlite",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "uuid"}},
+			args:     `"postgresql"`,
+			expected: "uuid::Uuid",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			actual, err := generateType(
+				t, createTypeTestGenerateRequest(testCase.engine, testCase.column, "RustType", testCase.args),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/code"
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+// createTypeTestGenerateRequest creates a request with a single query column where the template calls the type
+// mapping function with the column as the first argument followed by args.
+func createTypeTestGenerateRequest(engine string, column *plugin.Column, function string, args string) *plugin.GenerateRequest {
+	return &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: engine},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{
+				{Name: "public", Enums: []*plugin.Enum{{Name: "book_type", Vals: []string{"FICTION"}}}},
+				{Name: "audit", Enums: []*plugin.Enum{{Name: "action", Vals: []string{"INSERT"}}}},
+			},
+		},
+		Queries: []*plugin.Query{{Name: "query", Columns: []*plugin.Column{column}}},
+		PluginOptions: []byte(`{
+			"filename": "",
+			"template": "` + jsonString(`{{ `+function+` (index (index .Queries 0).Columns 0) `+args+` }}`) + `"
+		}`),
+	}
+}
+
+func generateType(t *testing.T, request *plugin.GenerateRequest) (string, error) {
+	t.Helper()
+
+	response, err := code.Generate(request)
+	if err != nil {
+		return "", err
+	}
+
+	return string(response.GetFiles()[0].GetContents()), nil
+}
+
+func TestTypeMapperFailure(t *testing.T) {
+	column := &plugin.Column{Type: &plugin.Identifier{Name: "text"}}
+	testCases := map[string]struct {
+		engine         string
+		template       string
+		expectedErrMsg string
+	}{
+		"invalid engine": {
+			engine:         "oracle",
+			expectedErrMsg: "TsType: invalid engine \"oracle\"",
+		},
+		"invalid engine argument": {
+			engine:         "postgresql",
+			template:       `"oracle"`,
+			expectedErrMsg: "TsType: invalid engine \"oracle\"",
+		},
+		"too many arguments": {
+			engine:         "postgresql",
+			template:       `"postgresql" "extra"`,
+			expectedErrMsg: "TsType: expected at most 1 argument after the column, got 2",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			_, err := generateType(t, createTypeTestGenerateRequest(testCase.engine, column, "TsType", testCase.template))
+			assert.ErrorContains(t, err, testCase.expectedErrMsg)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package code
+
+var tsTypeMapper = &typeMapper{
+	function: "TsType",
+	types: map[sqlType]string{
+		sqlTypeInt8:        "number",
+		sqlTypeInt16:       "number",
+		sqlTypeInt32:       "number",
+		sqlTypeInt64:       "bigint",
+		sqlTypeUint8:       "number",
+		sqlTypeUint16:      "number",
+		sqlTypeUint32:      "number",
+		sqlTypeUint64:      "bigint",
+		sqlTypeFloat32:     "number",
+		sqlTypeFloat64:     "number",
+		sqlTypeDecimal:     "string",
+		sqlTypeBool:        "boolean",
+		sqlTypeString:      "string",
+		sqlTypeUUID:        "string",
+		sqlTypeDate:        "Date",
+		sqlTypeTime:        "string",
+		sqlTypeTimestamp:   "Date",
+		sqlTypeTimestampTZ: "Date",
+		sqlTypeInterval:    "string",
+		sqlTypeJSON:        "any",
+		sqlTypeBytes:       "Uint8Array",
+		sqlTypeInet:        "string",
+		sqlTypeCIDR:        "string",
+		sqlTypeMacAddr:     "string",
+	},
+	anyType:  "any",
+	nullable: func(typ string) string { return typ + " | null" },
+	array:    func(typ string) string { return typ + "[]" },
+	override: func(override *typeOverride) *string { return override.TsType },
+}
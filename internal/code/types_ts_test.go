@@ -0,0 +1,117 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NMFR/sqlc-template/internal/protos/plugin"
+)
+
+func TestTsType(t *testing.T) {
+	testCases := map[string]struct {
+		engine   string
+		column   *plugin.Column
+		args     string
+		expected string
+	}{
+		"postgresql not null": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "int4"}},
+			expected: "number",
+		},
+		"postgresql null": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "text"}},
+			expected: "string | null",
+		},
+		"postgresql bigint": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "bigserial"}},
+			expected: "bigint",
+		},
+		"postgresql numeric": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "pg_catalog.numeric"}},
+			expected: "string",
+		},
+		"postgresql timestamp": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "timestamptz"}},
+			expected: "Date | null",
+		},
+		"postgresql array": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, IsArray: true, ArrayDims: 1, Type: &plugin.Identifier{Name: "text"}},
+			expected: "string[]",
+		},
+		"postgresql null multi dimensional array": {
+			engine:   "postgresql",
+			column:   &plugin.Column{IsArray: true, ArrayDims: 2, Type: &plugin.Identifier{Name: "bool"}},
+			expected: "boolean[][] | null",
+		},
+		"postgresql sqlc.slice": {
+			engine:   "postgresql",
+			column:   &plugin.Column{IsSqlcSlice: true, Type: &plugin.Identifier{Name: "int8"}},
+			expected: "bigint[]",
+		},
+		"postgresql enum": {
+			engine:   "postgresql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "book_type"}},
+			expected: "BookType | null",
+		},
+		"postgresql unknown type": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "tsvector"}},
+			expected: "any",
+		},
+		"postgresql void": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "void"}},
+			expected: "any",
+		},
+		"postgresql inet": {
+			engine:   "postgresql",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "inet"}},
+			expected: "string",
+		},
+		"mysql tinyint(1)": {
+			engine:   "mysql",
+			column:   &plugin.Column{NotNull: true, Length: 1, Type: &plugin.Identifier{Name: "tinyint"}},
+			expected: "boolean",
+		},
+		"mysql unsigned bigint": {
+			engine:   "mysql",
+			column:   &plugin.Column{NotNull: true, Unsigned: true, Type: &plugin.Identifier{Name: "bigint"}},
+			expected: "bigint",
+		},
+		"mysql blob": {
+			engine:   "mysql",
+			column:   &plugin.Column{Type: &plugin.Identifier{Name: "longblob"}},
+			expected: "Uint8Array | null",
+		},
+		"sqlite integer": {
+			engine:   "sqlite",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "INTEGER"}},
+			expected: "bigint",
+		},
+		"engine argument": {
+			engine:   "sqlite",
+			column:   &plugin.Column{NotNull: true, Type: &plugin.Identifier{Name: "jsonb"}},
+			args:     `"postgresql"`,
+			expected: "any",
+		},
+	}
+
+	for testName, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testName, func(t *testing.T) {
+			actual, err := generateType(
+				t, createTypeTestGenerateRequest(testCase.engine, testCase.column, "TsType", testCase.args),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
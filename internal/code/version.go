@@ -0,0 +1,21 @@
+package code
+
+import "runtime/debug"
+
+// Version is the plugin version, set when building the plugin with
+// `-ldflags "-X github.com/NMFR/sqlc-template/internal/code.Version=v1.2.0"`.
+var Version = ""
+
+// getVersion returns the plugin version, the Go module version is used when the version was not set at build time,
+// for example when the plugin is installed with `go install`.
+func getVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	return "unknown"
+}